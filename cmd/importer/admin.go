@@ -0,0 +1,18 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/flohansen/documenter/internal/admin"
+	"github.com/flohansen/documenter/internal/app"
+	"github.com/flohansen/documenter/internal/webhook"
+)
+
+// serveAdmin exposes the admin API at the configured address until the
+// context is cancelled. The delivery log of the dispatcher is served as well.
+func serveAdmin(ctx context.Context, cfg app.AdminConfig, cli *app.Importer, dispatcher *webhook.Dispatcher) {
+	log.Printf("serving admin api at %s", cfg.Address)
+	serve(ctx, "admin api", cfg.Address, admin.NewHandler(cfg.Token, cli.TriggerSection,
+		admin.WithDeliveries(dispatcher.Deliveries)))
+}
@@ -1,15 +1,16 @@
 package main
 
 import (
+	"context"
 	"flag"
-	"fmt"
 	"log"
 	"os"
 
 	"github.com/flohansen/documenter/internal/app"
+	"github.com/flohansen/documenter/internal/metrics"
 	"github.com/flohansen/documenter/internal/repository"
-	"github.com/jackc/pgx/v5/pgxpool"
-	"gopkg.in/yaml.v3"
+	"github.com/flohansen/documenter/internal/tracing"
+	"go.opentelemetry.io/otel"
 )
 
 type flags struct {
@@ -18,44 +19,96 @@ type flags struct {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "validate":
+			os.Exit(validate(os.Args[2:]))
+		case "schema":
+			os.Exit(schema(os.Args[2:]))
+		case "migrate":
+			os.Exit(migrate(os.Args[2:]))
+		case "trigger":
+			os.Exit(trigger(os.Args[2:]))
+		}
+	}
+
 	var flags flags
 	flag.StringVar(&flags.ConfigPath, "config", "documenter.config.yaml", "The path to the configuration file")
-	flag.StringVar(&flags.Database, "database", "postgresql://localhost:5432/postgres", "The connection string used to connect to the PostgreSQL database")
+	flag.StringVar(&flags.Database, "database", "", "The connection string used to connect to the database (deprecated, use database.dsn or DOCUMENTER_DATABASE_DSN instead)")
 	flag.Parse()
 
-	config, err := readConfig(flags.ConfigPath)
+	config, err := app.ReadConfig(flags.ConfigPath)
 	if err != nil {
 		log.Fatalf("could not read config: %v", err)
 	}
+	if err := config.Validate(); err != nil {
+		log.Fatalf("invalid config (run \"importer validate\" for details):\n%v", err)
+	}
+	for _, warning := range config.Warnings() {
+		log.Printf("config warning: %s", warning)
+	}
 
 	ctx := app.SignalContext()
-	pool, err := pgxpool.New(ctx, flags.Database)
-	if err != nil {
-		log.Fatalf("could not create db pool: %v", err)
+	if config.Tracing.Enabled {
+		tp, err := tracing.NewProvider(ctx, config.Tracing.Endpoint,
+			tracing.WithServiceName(config.Tracing.ServiceName),
+			tracing.WithInsecure(config.Tracing.Insecure))
+		if err != nil {
+			log.Fatalf("could not create tracer provider: %v", err)
+		}
+		defer tp.Shutdown(context.Background())
+
+		otel.SetTracerProvider(tp)
 	}
-	if err := pool.Ping(ctx); err != nil {
-		log.Fatalf("could not ping database: %v", err)
+
+	if len(flags.Database) > 0 {
+		config.Database.DSN = flags.Database
 	}
 
-	repo := repository.NewDocRepoPostgres(pool)
+	m := metrics.New()
+	if len(config.Metrics.Address) > 0 {
+		go serveMetrics(ctx, config.Metrics, m)
+	}
 
-	cli := app.NewImporter(repo, config)
-	if err := cli.Run(ctx); err != nil {
-		log.Fatalf("cli error: %v", err)
+	storage, err := openStorage(ctx, config, m)
+	if err != nil {
+		log.Fatal(err)
 	}
-}
+	defer storage.close()
 
-func readConfig(name string) (app.Config, error) {
-	f, err := os.Open(name)
+	cli := app.NewImporter(storage.repo, config)
+	cli.Locker = storage.locker
+	dispatcher := startWebhooks(ctx, cli.Logger)
+	cli.Webhooks = dispatcher
+	cli.Reloads, err = app.WatchConfig(ctx, flags.ConfigPath, cli.Logger)
 	if err != nil {
-		return app.Config{}, fmt.Errorf("could not open file: %w", err)
+		log.Fatalf("could not watch config: %v", err)
 	}
-	defer f.Close()
 
-	var config app.Config
-	if err := yaml.NewDecoder(f).Decode(&config); err != nil {
-		return app.Config{}, fmt.Errorf("json decode error: %w", err)
+	if len(config.Push.Address) > 0 {
+		go servePush(ctx, config.Push, cli)
+	}
+	if len(config.Admin.Address) > 0 {
+		go serveAdmin(ctx, config.Admin, cli, dispatcher)
 	}
 
-	return config, nil
+	if err := cli.Run(ctx); err != nil {
+		log.Fatalf("cli error: %v", err)
+	}
+}
+
+// poolOptions returns the options of database connections configured in the
+// database section.
+func poolOptions(cfg app.DatabaseConfig) []repository.PoolOption {
+	return []repository.PoolOption{
+		repository.WithMaxConns(cfg.MaxConns),
+		repository.WithMinConns(cfg.MinConns),
+		repository.WithConnectTimeout(cfg.ConnectTimeout),
+		repository.WithTLS(
+			cfg.TLS.Mode.String(),
+			cfg.TLS.CAFile,
+			cfg.TLS.CertFile,
+			cfg.TLS.KeyFile,
+		),
+	}
 }
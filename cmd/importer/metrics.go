@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/flohansen/documenter/internal/app"
+	"github.com/flohansen/documenter/internal/metrics"
+)
+
+const defaultMetricsPath = "/metrics"
+
+// serveMetrics exposes the metrics at the configured address until the
+// context is cancelled.
+func serveMetrics(ctx context.Context, cfg app.MetricsConfig, m *metrics.Metrics) {
+	path := cfg.Path
+	if len(path) == 0 {
+		path = defaultMetricsPath
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, m.Handler())
+
+	log.Printf("serving metrics at %s%s", cfg.Address, path)
+	serve(ctx, "metrics", cfg.Address, mux)
+}
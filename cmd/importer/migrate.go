@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/flohansen/documenter/internal/app"
+	"github.com/flohansen/documenter/internal/repository"
+)
+
+const migrateUsage = `usage: importer migrate [flags] up|down|status
+
+  up      apply all pending migrations
+  down    revert the last -steps migrations
+  status  print the schema version and pending migrations
+`
+
+// migrate runs the migrate command, which applies or reverts the embedded
+// database migrations or prints their status. It returns the exit code of the
+// command.
+func migrate(args []string) int {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), migrateUsage)
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("config", "documenter.config.yaml", "The path to the configuration file")
+	database := fs.String("database", "", "The connection string of the database (overrides database.dsn)")
+	steps := fs.Int("steps", 1, "The number of migrations to revert with down")
+	fs.Parse(args)
+
+	// Allow flags after the action, e.g. "migrate down -steps 2".
+	action := fs.Arg(0)
+	fs.Parse(fs.Args()[min(1, fs.NArg()):])
+	if action != "up" && action != "down" && action != "status" {
+		fs.Usage()
+		return 2
+	}
+
+	config, err := app.ReadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not read config: %v\n", err)
+		return 2
+	}
+	if len(*database) > 0 {
+		config.Database.DSN = *database
+	}
+
+	switch action {
+	case "up":
+		version, err := migrateUp(config.Database)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		fmt.Printf("schema is at version %d\n", version)
+	case "down":
+		err := withMigrator(config.Database, func(m *repository.Migrator) error {
+			return m.Down(*steps)
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		fmt.Printf("reverted %d migration(s)\n", *steps)
+	case "status":
+		var status repository.MigrationStatus
+		err := withMigrator(config.Database, func(m *repository.Migrator) (err error) {
+			status, err = m.Status()
+			return err
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		fmt.Print(formatMigrationStatus(status))
+	}
+
+	return 0
+}
+
+// migrateUp applies all pending migrations and returns the resulting schema
+// version.
+func migrateUp(cfg app.DatabaseConfig) (version uint, err error) {
+	err = withMigrator(cfg, func(m *repository.Migrator) error {
+		if err := m.Up(); err != nil {
+			return err
+		}
+
+		status, err := m.Status()
+		version = status.Version
+		return err
+	})
+
+	return version, err
+}
+
+// withMigrator connects a migrator to the configured database and passes it
+// to fn. The backend is selected by the scheme of the connection string.
+func withMigrator(cfg app.DatabaseConfig, fn func(m *repository.Migrator) error) error {
+	var m *repository.Migrator
+	var err error
+	switch {
+	case repository.IsFilesystemDSN(cfg.DSN):
+		return fmt.Errorf("the filesystem backend has no migrations")
+	case repository.IsSQLiteDSN(cfg.DSN):
+		m, err = repository.NewMigratorSQLite(cfg.DSN)
+	default:
+		m, err = repository.NewMigratorPostgres(cfg.DSN, poolOptions(cfg)...)
+	}
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	return fn(m)
+}
+
+func formatMigrationStatus(status repository.MigrationStatus) string {
+	pending := "none"
+	if len(status.Pending) > 0 {
+		versions := make([]string, 0, len(status.Pending))
+		for _, v := range status.Pending {
+			versions = append(versions, fmt.Sprint(v))
+		}
+		pending = strings.Join(versions, ", ")
+	}
+
+	return fmt.Sprintf("version: %d\ndirty: %t\nlatest: %d\npending: %s\n",
+		status.Version, status.Dirty, status.Latest, pending)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/flohansen/documenter/internal/app"
+	"github.com/flohansen/documenter/internal/webhook"
+)
+
+const defaultPushPath = "/hooks/push"
+
+// servePush accepts push webhooks at the configured address until the
+// context is cancelled and triggers the sections of pushed repositories.
+func servePush(ctx context.Context, cfg app.PushConfig, cli *app.Importer) {
+	path := cfg.Path
+	if len(path) == 0 {
+		path = defaultPushPath
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, webhook.NewPushHandler(cfg.Secret, cli.TriggerRepository))
+
+	log.Printf("accepting push webhooks at %s%s", cfg.Address, path)
+	serve(ctx, "push webhooks", cfg.Address, mux)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/flohansen/documenter/internal/app"
+)
+
+// schema runs the schema command, which prints the JSON schema of the
+// configuration file. It returns the exit code of the command.
+func schema(args []string) int {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	sections := fs.Bool("sections", false, "Print the schema of included section files instead")
+	fs.Parse(args)
+
+	b := app.ConfigSchema()
+	if *sections {
+		b = app.SectionsFileSchema()
+	}
+
+	if _, err := os.Stdout.Write(b); err != nil {
+		return 1
+	}
+
+	return 0
+}
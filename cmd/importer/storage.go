@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/flohansen/documenter/internal/app"
+	"github.com/flohansen/documenter/internal/repository"
+)
+
+// storage bundles the repository and locker of the configured database
+// backend.
+type storage struct {
+	repo   app.DocumentationRepository
+	locker app.SectionLocker // nil if the backend does not support replicas
+	close  func()
+}
+
+// openStorage connects to the database selected by the scheme of the
+// connection string, applies pending migrations if enabled and checks the
+// schema version. Compression ratios of written contents are reported to the
+// observer.
+func openStorage(ctx context.Context, config app.Config, observer repository.CompressionObserver) (storage, error) {
+	cfg := config.Database
+	objectStorage := len(config.ObjectStorage.Endpoint) > 0
+
+	switch {
+	case repository.IsFilesystemDSN(cfg.DSN):
+		if objectStorage {
+			return storage{}, fmt.Errorf("object storage is not supported by the filesystem backend")
+		}
+		return openFilesystem(cfg)
+	case repository.IsSQLiteDSN(cfg.DSN):
+		if objectStorage {
+			return storage{}, fmt.Errorf("object storage is not supported by the sqlite backend")
+		}
+		return openSQLite(ctx, cfg)
+	}
+
+	opts := []repository.DocRepoOption{
+		repository.WithCompression(compressionCodec(cfg.Compression)),
+		repository.WithCompressionObserver(observer),
+	}
+	if objectStorage {
+		blobs, err := openBlobStore(ctx, config.ObjectStorage)
+		if err != nil {
+			return storage{}, err
+		}
+		opts = append(opts, repository.WithBlobStore(blobs))
+	}
+
+	return openPostgres(ctx, cfg, opts...)
+}
+
+func openPostgres(ctx context.Context, cfg app.DatabaseConfig, opts ...repository.DocRepoOption) (storage, error) {
+	pool, err := repository.NewPostgresPool(ctx, cfg.DSN, poolOptions(cfg)...)
+	if err != nil {
+		return storage{}, fmt.Errorf("could not create db pool: %w", err)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return storage{}, fmt.Errorf("could not ping database: %w", err)
+	}
+
+	if err := autoMigrate(cfg); err != nil {
+		pool.Close()
+		return storage{}, err
+	}
+	if err := repository.CheckSchemaVersion(ctx, pool); err != nil {
+		pool.Close()
+		return storage{}, fmt.Errorf("schema version check failed: %w", err)
+	}
+
+	locker := repository.NewSectionLockerPostgres(pool)
+	return storage{
+		repo:   repository.NewDocRepoPostgres(pool, opts...),
+		locker: locker,
+		close: func() {
+			locker.Close(context.Background())
+			pool.Close()
+		},
+	}, nil
+}
+
+func openSQLite(ctx context.Context, cfg app.DatabaseConfig) (storage, error) {
+	db, err := repository.OpenSQLite(cfg.DSN)
+	if err != nil {
+		return storage{}, err
+	}
+
+	if err := autoMigrate(cfg); err != nil {
+		db.Close()
+		return storage{}, err
+	}
+	if err := repository.CheckSchemaVersionSQLite(ctx, db); err != nil {
+		db.Close()
+		return storage{}, fmt.Errorf("schema version check failed: %w", err)
+	}
+
+	return storage{
+		repo:  repository.NewDocRepoSQLite(db),
+		close: func() { db.Close() },
+	}, nil
+}
+
+// openFilesystem writes documentations to a directory instead of a database,
+// which needs neither migrations nor a schema version check.
+func openFilesystem(cfg app.DatabaseConfig) (storage, error) {
+	repo, err := repository.NewDocRepoFilesystem(cfg.DSN)
+	if err != nil {
+		return storage{}, err
+	}
+
+	return storage{
+		repo:  repo,
+		close: func() {},
+	}, nil
+}
+
+// openBlobStore connects to the configured object storage and checks that
+// the bucket exists.
+func openBlobStore(ctx context.Context, cfg app.ObjectStorageConfig) (*repository.BlobStoreS3, error) {
+	blobs, err := repository.NewBlobStoreS3(cfg.Endpoint, cfg.Bucket,
+		repository.WithS3Credentials(cfg.AccessKey, cfg.SecretKey),
+		repository.WithS3Region(cfg.Region),
+		repository.WithS3Prefix(cfg.Prefix),
+		repository.WithS3Insecure(cfg.Insecure))
+	if err != nil {
+		return nil, err
+	}
+	if err := blobs.Ping(ctx); err != nil {
+		return nil, err
+	}
+
+	return blobs, nil
+}
+
+// compressionCodec returns the repository codec of the configured codec.
+func compressionCodec(codec app.CompressionCodec) repository.Codec {
+	switch codec {
+	case app.CompressionCodecGzip:
+		return repository.CodecGzip
+	case app.CompressionCodecNone:
+		return repository.CodecNone
+	default:
+		return repository.CodecZstd
+	}
+}
+
+// autoMigrate applies pending migrations if enabled in the database section.
+func autoMigrate(cfg app.DatabaseConfig) error {
+	if !cfg.AutoMigrate {
+		return nil
+	}
+
+	version, err := migrateUp(cfg)
+	if err != nil {
+		return fmt.Errorf("could not migrate database: %w", err)
+	}
+
+	log.Printf("database schema is at version %d", version)
+	return nil
+}
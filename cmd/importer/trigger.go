@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/flohansen/documenter/internal/admin"
+	"github.com/flohansen/documenter/internal/app"
+)
+
+const triggerUsage = `usage: importer trigger [flags] <section>...
+
+Scrapes the sections with the given qualified names (e.g. "my-service" or
+"team/my-service") in a running importer and reports the outcome of each
+scrape. The importer is reached via the admin API configured in admin.address.
+`
+
+// trigger runs the trigger command, which requests immediate scrapes of
+// sections from a running importer. It returns the exit code of the command.
+func trigger(args []string) int {
+	fs := flag.NewFlagSet("trigger", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), triggerUsage)
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("config", "documenter.config.yaml", "The path to the configuration file")
+	url := fs.String("url", "", "The base URL of the admin API (defaults to http://<admin.address>)")
+	timeout := fs.Duration("timeout", 5*time.Minute, "The time to wait for the scrapes to finish")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	config, err := app.ReadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not read config: %v\n", err)
+		return 2
+	}
+
+	baseURL := *url
+	if len(baseURL) == 0 {
+		if len(config.Admin.Address) == 0 {
+			fmt.Fprintln(os.Stderr, "admin api is not configured, set admin.address or -url")
+			return 2
+		}
+		baseURL = adminURL(config.Admin.Address)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	client := admin.NewClient(baseURL, config.Admin.Token, http.DefaultClient)
+	results, err := client.Trigger(ctx, fs.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not trigger sections: %v\n", err)
+		return 1
+	}
+
+	code := 0
+	for _, result := range results {
+		if len(result.Error) > 0 {
+			fmt.Printf("%s: %s\n", result.Section, result.Error)
+			code = 1
+			continue
+		}
+
+		fmt.Printf("%s: scraped\n", result.Section)
+	}
+
+	return code
+}
+
+// adminURL returns the base URL of the admin API listening at the address.
+// Addresses without a host are reached via localhost.
+func adminURL(address string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "http://" + address
+	}
+	if len(host) == 0 {
+		host = "localhost"
+	}
+
+	return "http://" + net.JoinHostPort(host, port)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/flohansen/documenter/internal/app"
+)
+
+// validate runs the validate command, which checks the configuration file and
+// reports all problems found. It returns the exit code of the command.
+func validate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	configPath := fs.String("config", "documenter.config.yaml", "The path to the configuration file")
+	fs.Parse(args)
+
+	problems, err := app.ValidateConfigFile(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not validate config: %v\n", err)
+		return 2
+	}
+
+	var failures int
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, formatProblem(*configPath, problem))
+		if !problem.Warning {
+			failures++
+		}
+	}
+	if failures > 0 {
+		fmt.Fprintf(os.Stderr, "found %d problem(s)\n", failures)
+		return 1
+	}
+
+	fmt.Println("config is valid")
+	return 0
+}
+
+// formatProblem formats the problem like compiler errors (file:line: message),
+// so editors are able to jump to the affected line.
+func formatProblem(name string, problem app.ConfigProblem) string {
+	if len(problem.File) > 0 {
+		name = problem.File
+	}
+
+	location := name
+	if problem.Line > 0 {
+		location = fmt.Sprintf("%s:%d", name, problem.Line)
+	}
+
+	message := problem.Message
+	if problem.Warning {
+		message = "warning: " + message
+	}
+	if len(problem.Path) > 0 {
+		message = fmt.Sprintf("%s: %s", problem.Path, problem.Message)
+	}
+
+	return fmt.Sprintf("%s: %s", location, message)
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"context"
+
+	"github.com/flohansen/documenter/internal/webhook"
+)
+
+// startWebhooks starts the dispatcher delivering the configured webhooks
+// until the context is cancelled. It is started regardless of the
+// configuration, so webhooks added by a reload are delivered as well.
+func startWebhooks(ctx context.Context, logger webhook.Logger) *webhook.Dispatcher {
+	dispatcher := webhook.NewDispatcher(webhook.WithLogger(logger))
+	go dispatcher.Run(ctx)
+
+	return dispatcher
+}
@@ -0,0 +1,199 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/flohansen/documenter/internal/webhook"
+)
+
+// TriggerPath is the path of the endpoint triggering scrapes of sections.
+const TriggerPath = "/admin/trigger"
+
+// DeliveriesPath is the path of the endpoint listing webhook deliveries.
+const DeliveriesPath = "/admin/deliveries"
+
+// maxRequestSize is the largest accepted request body.
+const maxRequestSize = 1 << 20
+
+// Trigger scrapes the section with the given qualified name and returns the
+// outcome of the scrape.
+type Trigger func(ctx context.Context, section string) error
+
+// TriggerRequest is the request body of the trigger endpoint.
+type TriggerRequest struct {
+	Sections []string `json:"sections"`
+}
+
+// TriggerResponse is the response body of the trigger endpoint, containing
+// the outcome of each requested section in the order of the request.
+type TriggerResponse struct {
+	Results []TriggerResult `json:"results"`
+}
+
+// TriggerResult is the outcome of the scrape of a single section.
+type TriggerResult struct {
+	Section string `json:"section"`
+	Error   string `json:"error,omitempty"` // Empty if the section was scraped
+}
+
+// DeliveriesResponse is the response body of the deliveries endpoint,
+// containing the delivery log ordered from the oldest to the latest attempt.
+type DeliveriesResponse struct {
+	Deliveries []webhook.Delivery `json:"deliveries"`
+}
+
+// Handler serves the admin API. Every request must carry the token as bearer
+// token in the Authorization header.
+type Handler struct {
+	mux        *http.ServeMux
+	token      string
+	trigger    Trigger
+	deliveries func() []webhook.Delivery
+}
+
+// HandlerOption configures a Handler.
+type HandlerOption func(*Handler)
+
+// WithDeliveries exposes the webhook delivery log returned by deliveries,
+// e.g. webhook.Dispatcher.Deliveries, at DeliveriesPath.
+func WithDeliveries(deliveries func() []webhook.Delivery) HandlerOption {
+	return func(h *Handler) {
+		h.deliveries = deliveries
+	}
+}
+
+// NewHandler creates the admin API authenticated by the token.
+func NewHandler(token string, trigger Trigger, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		mux:     http.NewServeMux(),
+		token:   token,
+		trigger: trigger,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.mux.HandleFunc("POST "+TriggerPath, h.handleTrigger)
+	if h.deliveries != nil {
+		h.mux.HandleFunc("GET "+DeliveriesPath, h.handleDeliveries)
+	}
+
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	h.mux.ServeHTTP(w, r)
+}
+
+// handleTrigger scrapes the requested sections concurrently and responds
+// once all scrapes finished or the request was cancelled.
+func (h *Handler) handleTrigger(w http.ResponseWriter, r *http.Request) {
+	var req TriggerRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if len(req.Sections) == 0 {
+		http.Error(w, "sections are required", http.StatusBadRequest)
+		return
+	}
+
+	results := make([]TriggerResult, len(req.Sections))
+	var wg sync.WaitGroup
+	for n, section := range req.Sections {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[n] = TriggerResult{Section: section}
+			if err := h.trigger(r.Context(), section); err != nil {
+				results[n].Error = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TriggerResponse{Results: results})
+}
+
+// handleDeliveries responds with the webhook delivery log.
+func (h *Handler) handleDeliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries := h.deliveries()
+	if deliveries == nil {
+		deliveries = []webhook.Delivery{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(DeliveriesResponse{Deliveries: deliveries})
+}
+
+// Client calls the admin API of a running importer.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+// NewClient creates a client of the admin API at the base URL, e.g.
+// "http://localhost:8081".
+func NewClient(baseURL, token string, httpClient *http.Client) *Client {
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+	}
+}
+
+// Trigger scrapes the sections with the given qualified names and waits for
+// the outcome of each scrape.
+func (c *Client) Trigger(ctx context.Context, sections []string) ([]TriggerResult, error) {
+	body, err := json.Marshal(TriggerRequest{Sections: sections})
+	if err != nil {
+		return nil, fmt.Errorf("json encode error: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+TriggerPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request error: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return nil, fmt.Errorf("unexpected status code %d: %s", res.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var resp TriggerResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("json decode error: %w", err)
+	}
+	if len(resp.Results) != len(sections) {
+		return nil, errors.New("incomplete response")
+	}
+
+	return resp.Results, nil
+}
@@ -0,0 +1,162 @@
+package admin_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flohansen/documenter/internal/admin"
+	"github.com/flohansen/documenter/internal/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	trigger := func(ctx context.Context, section string) error {
+		if section == "broken" {
+			return errors.New("scrape error: clone error")
+		}
+		return nil
+	}
+
+	t.Run("should report outcome of each section", func(t *testing.T) {
+		// assign
+		handler := admin.NewHandler("token", trigger)
+		req := httptest.NewRequest(http.MethodPost, admin.TriggerPath, strings.NewReader(`{"sections":["a","broken","team/b"]}`))
+		req.Header.Set("Authorization", "Bearer token")
+		rec := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(rec, req)
+
+		// assert
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"results":[
+			{"section":"a"},
+			{"section":"broken","error":"scrape error: clone error"},
+			{"section":"team/b"}
+		]}`, rec.Body.String())
+	})
+
+	t.Run("should reject requests without valid token", func(t *testing.T) {
+		for _, header := range []string{"", "token", "Bearer other"} {
+			// assign
+			handler := admin.NewHandler("token", trigger)
+			req := httptest.NewRequest(http.MethodPost, admin.TriggerPath, strings.NewReader(`{"sections":["a"]}`))
+			req.Header.Set("Authorization", header)
+			rec := httptest.NewRecorder()
+
+			// act
+			handler.ServeHTTP(rec, req)
+
+			// assert
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		}
+	})
+
+	t.Run("should reject requests without sections", func(t *testing.T) {
+		// assign
+		handler := admin.NewHandler("token", trigger)
+		req := httptest.NewRequest(http.MethodPost, admin.TriggerPath, strings.NewReader(`{"sections":[]}`))
+		req.Header.Set("Authorization", "Bearer token")
+		rec := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(rec, req)
+
+		// assert
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "sections are required\n", rec.Body.String())
+	})
+
+	t.Run("should return webhook deliveries", func(t *testing.T) {
+		// assign
+		deliveries := func() []webhook.Delivery {
+			return []webhook.Delivery{{
+				ID:         "id",
+				URL:        "https://hooks.example.com",
+				Event:      webhook.Event{Type: webhook.EventDocumentationChanged, Namespace: "default", Name: "a", Hash: "hash", Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+				Attempt:    1,
+				Status:     webhook.DeliveryStatusRetrying,
+				StatusCode: http.StatusBadGateway,
+				Error:      "unexpected status code 502",
+				Time:       time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC),
+			}}
+		}
+		handler := admin.NewHandler("token", trigger, admin.WithDeliveries(deliveries))
+		req := httptest.NewRequest(http.MethodGet, admin.DeliveriesPath, nil)
+		req.Header.Set("Authorization", "Bearer token")
+		rec := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(rec, req)
+
+		// assert
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"deliveries":[{
+			"id":"id",
+			"url":"https://hooks.example.com",
+			"event":{"event":"documentation.changed","namespace":"default","name":"a","hash":"hash","time":"2024-01-01T00:00:00Z"},
+			"attempt":1,
+			"status":"retrying",
+			"statusCode":502,
+			"error":"unexpected status code 502",
+			"time":"2024-01-01T00:00:01Z"
+		}]}`, rec.Body.String())
+	})
+
+	t.Run("should not serve deliveries without delivery log", func(t *testing.T) {
+		// assign
+		handler := admin.NewHandler("token", trigger)
+		req := httptest.NewRequest(http.MethodGet, admin.DeliveriesPath, nil)
+		req.Header.Set("Authorization", "Bearer token")
+		rec := httptest.NewRecorder()
+
+		// act
+		handler.ServeHTTP(rec, req)
+
+		// assert
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestClient_Trigger(t *testing.T) {
+	t.Run("should return outcome of each section", func(t *testing.T) {
+		// assign
+		server := httptest.NewServer(admin.NewHandler("token", func(ctx context.Context, section string) error {
+			if section == "unknown" {
+				return errors.New("section is not running")
+			}
+			return nil
+		}))
+		defer server.Close()
+		client := admin.NewClient(server.URL+"/", "token", server.Client())
+
+		// act
+		results, err := client.Trigger(context.Background(), []string{"a", "unknown"})
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []admin.TriggerResult{
+			{Section: "a"},
+			{Section: "unknown", Error: "section is not running"},
+		}, results)
+	})
+
+	t.Run("should return error for rejected request", func(t *testing.T) {
+		// assign
+		server := httptest.NewServer(admin.NewHandler("token", nil))
+		defer server.Close()
+		client := admin.NewClient(server.URL, "other", server.Client())
+
+		// act
+		results, err := client.Trigger(context.Background(), []string{"a"})
+
+		// assert
+		assert.EqualError(t, err, "unexpected status code 401: unauthorized")
+		assert.Nil(t, results)
+	})
+}
@@ -1,32 +1,132 @@
 package app
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path"
+	"reflect"
 	"time"
 
+	"github.com/flohansen/documenter/internal/domain"
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the main application configuration structure.
 // It contains settings for documentation scraping, scraping intervals, and logging.
 type Config struct {
-	Docs     DocsConfig     `yaml:"docs"`     // Documentation configuration
-	Scraping ScrapingConfig `yaml:"scraping"` // Scraping behavior configuration
-	Logging  LoggingConfig  `yaml:"logging"`  // Logging output configuration
+	Docs          DocsConfig          `yaml:"docs"`          // Documentation configuration
+	Scraping      ScrapingConfig      `yaml:"scraping"`      // Scraping behavior configuration
+	Logging       LoggingConfig       `yaml:"logging"`       // Logging output configuration
+	Tracing       TracingConfig       `yaml:"tracing"`       // Tracing export configuration
+	Include       []string            `yaml:"include"`       // Glob patterns of files with additional sections
+	Database      DatabaseConfig      `yaml:"database"`      // Database connection configuration
+	ObjectStorage ObjectStorageConfig `yaml:"objectStorage"` // Object storage of documentation contents (optional)
+	Metrics       MetricsConfig       `yaml:"metrics"`       // Metrics endpoint configuration
+	Webhooks      []WebhookConfig     `yaml:"webhooks"`      // Webhooks notified about changed documentations
+	Push          PushConfig          `yaml:"push"`          // Endpoint receiving push webhooks
+	Admin         AdminConfig         `yaml:"admin"`         // Admin API configuration
+}
+
+// ReadConfig reads and parses the configuration file with the given name. The
+// sections of all files matched by the include patterns are appended to
+// DocsConfig.Sections.
+func ReadConfig(name string) (Config, error) {
+	set, err := readConfigFiles(name)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return parseConfigFiles(set)
+}
+
+// ParseConfig parses the YAML encoded configuration. Environment variables in
+// the form ${VAR} or ${VAR:-default} are expanded and keys with the suffix
+// "_file" are replaced by the content of the referenced file. Finally, fields
+// with an env tag are overridden by their environment variables.
+func ParseConfig(b []byte) (Config, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		return Config{}, fmt.Errorf("yaml decode error: %w", err)
+	}
+
+	if errs := interpolateConfig(&root, os.LookupEnv); len(errs) > 0 {
+		return Config{}, fmt.Errorf("interpolation error: %w", errors.Join(errs...))
+	}
+
+	var config Config
+	if err := root.Decode(&config); err != nil {
+		return Config{}, fmt.Errorf("yaml decode error: %w", err)
+	}
+
+	if errs := applyEnvOverrides(&config, os.LookupEnv); len(errs) > 0 {
+		return Config{}, fmt.Errorf("environment override error: %w", errors.Join(errs...))
+	}
+
+	return config, nil
 }
 
 // DocsConfig contains configuration for documentation sections to be processed.
 type DocsConfig struct {
 	Sections []SectionConfig `yaml:"sections"` // List of documentation sections
+	Prune    PruneConfig     `yaml:"prune"`    // Handling of documentations without a section
+}
+
+// PruneConfig defines how documentations are handled whose sections were
+// removed from the configuration.
+type PruneConfig struct {
+	Mode PruneMode `yaml:"mode"` // What to do with documentations without a section
 }
 
 // SectionConfig defines configuration for a single documentation section.
 // Each section represents a source of documentation with its type, location, and access credentials.
 type SectionConfig struct {
-	Name   string      `yaml:"name"`   // Human-readable name for the section
-	Type   SectionType `yaml:"type"`   // Type of the documentation source (e.g., git)
-	URL    string      `yaml:"url"`    // URL or path to the documentation source
-	SSHKey string      `yaml:"sshKey"` // SSH key for authentication (if required)
+	Namespace   string          `yaml:"namespace"`   // Namespace isolating the documentation (defaults to "default")
+	Name        string          `yaml:"name"`        // Human-readable name for the section (unique per namespace)
+	Type        SectionType     `yaml:"type"`        // Type of the documentation source (e.g., git)
+	URL         string          `yaml:"url"`         // URL or path to the documentation source
+	SSHKey      string          `yaml:"sshKey"`      // SSH key for authentication (if required)
+	Title       string          `yaml:"title"`       // Display title of the documentation
+	Description string          `yaml:"description"` // Short description of the documentation
+	Owner       string          `yaml:"owner"`       // Team owning the documentation
+	Tags        []string        `yaml:"tags"`        // Tags to filter documentations by
+	Category    string          `yaml:"category"`    // Category path with "/" separated levels, e.g. platform/payments
+	Discovery   DiscoveryConfig `yaml:"discovery"`   // Repository discovery settings (discovery sections only)
+}
+
+// Key returns the key of the section's documentation. Sections without a
+// namespace belong to the default namespace.
+func (s SectionConfig) Key() domain.DocumentationKey {
+	namespace := s.Namespace
+	if len(namespace) == 0 {
+		namespace = domain.DefaultNamespace
+	}
+
+	return domain.DocumentationKey{Namespace: namespace, Name: s.Name}
+}
+
+// Metadata returns the metadata persisted with the section's documentation.
+func (s SectionConfig) Metadata() domain.Metadata {
+	return domain.Metadata{
+		Title:       s.Title,
+		Description: s.Description,
+		Owner:       s.Owner,
+		Tags:        s.Tags,
+		Category:    s.Category,
+	}
+}
+
+// DiscoveryConfig defines which repositories of an organization are turned
+// into Git sections by a discovery section. The section URL is the base URL
+// of the hosting API and the generated sections are named
+// "<section name>/<repository name>".
+type DiscoveryConfig struct {
+	Provider        DiscoveryProvider `yaml:"provider"`        // API flavour of the hosting service
+	Organization    string            `yaml:"organization"`    // Organization (or GitLab group) to list repositories of
+	Topic           string            `yaml:"topic"`           // Topic repositories must have (optional)
+	NamePattern     string            `yaml:"namePattern"`     // Regular expression repository names must match (optional)
+	IncludeArchived bool              `yaml:"includeArchived"` // Whether archived repositories are included
+	Token           string            `yaml:"token"`           // API token used to list repositories (optional)
 }
 
 // ScrapingConfig defines how frequently the application should scrape documentation sources.
@@ -39,12 +139,109 @@ type LoggingConfig struct {
 	Format LoggingFormat `yaml:"format"` // Format for log messages
 }
 
+// TracingConfig specifies whether and where traces are exported to.
+// Spans are sent via OTLP/HTTP to the configured collector endpoint.
+type TracingConfig struct {
+	Enabled     bool   `yaml:"enabled"`     // Whether tracing is enabled
+	Endpoint    string `yaml:"endpoint"`    // Collector endpoint in the form host:port
+	Insecure    bool   `yaml:"insecure"`    // Use plain HTTP instead of HTTPS
+	ServiceName string `yaml:"serviceName"` // Service name reported with each span
+}
+
+// DatabaseConfig defines how to connect to the database. Every field can be
+// overridden by the environment variable named in its env tag, so credentials
+// do not have to be stored in the configuration file or passed as arguments.
+type DatabaseConfig struct {
+	DSN            string            `yaml:"dsn" env:"DOCUMENTER_DATABASE_DSN"`                        // Connection string of the database
+	MaxConns       int32             `yaml:"maxConns" env:"DOCUMENTER_DATABASE_MAX_CONNS"`             // Maximum number of pooled connections
+	MinConns       int32             `yaml:"minConns" env:"DOCUMENTER_DATABASE_MIN_CONNS"`             // Minimum number of pooled connections
+	ConnectTimeout time.Duration     `yaml:"connectTimeout" env:"DOCUMENTER_DATABASE_CONNECT_TIMEOUT"` // Timeout for establishing connections
+	TLS            DatabaseTLSConfig `yaml:"tls"`                                                      // TLS configuration of connections
+	AutoMigrate    bool              `yaml:"autoMigrate" env:"DOCUMENTER_DATABASE_AUTO_MIGRATE"`       // Apply pending migrations on start
+	Compression    CompressionCodec  `yaml:"compression" env:"DOCUMENTER_DATABASE_COMPRESSION"`        // Codec of stored contents (PostgreSQL only)
+}
+
+// ObjectStorageConfig defines the S3-compatible object storage holding the
+// contents of documentations, so the PostgreSQL database only holds their
+// metadata. Object storage is disabled if no endpoint is set.
+type ObjectStorageConfig struct {
+	Endpoint  string `yaml:"endpoint" env:"DOCUMENTER_OBJECT_STORAGE_ENDPOINT"`    // API endpoint in the form host:port
+	Bucket    string `yaml:"bucket" env:"DOCUMENTER_OBJECT_STORAGE_BUCKET"`        // Existing bucket of the contents
+	Prefix    string `yaml:"prefix" env:"DOCUMENTER_OBJECT_STORAGE_PREFIX"`        // Prefix of all object keys
+	Region    string `yaml:"region" env:"DOCUMENTER_OBJECT_STORAGE_REGION"`        // Region of the bucket
+	AccessKey string `yaml:"accessKey" env:"DOCUMENTER_OBJECT_STORAGE_ACCESS_KEY"` // Access key ID
+	SecretKey string `yaml:"secretKey" env:"DOCUMENTER_OBJECT_STORAGE_SECRET_KEY"` // Secret access key
+	Insecure  bool   `yaml:"insecure" env:"DOCUMENTER_OBJECT_STORAGE_INSECURE"`    // Use plain HTTP instead of HTTPS
+}
+
+// MetricsConfig specifies where the Prometheus metrics endpoint listens.
+// Metrics are disabled if no address is set.
+type MetricsConfig struct {
+	Address string `yaml:"address" env:"DOCUMENTER_METRICS_ADDRESS"` // Listen address in the form host:port, e.g. :9090
+	Path    string `yaml:"path" env:"DOCUMENTER_METRICS_PATH"`       // HTTP path of the endpoint (defaults to /metrics)
+}
+
+// PushConfig specifies where the endpoint receiving push webhooks of GitHub,
+// Gitea and GitLab listens. A push triggers an immediate scrape of the
+// sections of the pushed repository. The endpoint is disabled if no address
+// is set.
+type PushConfig struct {
+	Address string `yaml:"address" env:"DOCUMENTER_PUSH_ADDRESS"` // Listen address in the form host:port, e.g. :8080
+	Path    string `yaml:"path" env:"DOCUMENTER_PUSH_PATH"`       // HTTP path of the endpoint (defaults to /hooks/push)
+	Secret  string `yaml:"secret" env:"DOCUMENTER_PUSH_SECRET"`   // Secret configured for the webhooks at the hosting service
+}
+
+// AdminConfig specifies where the admin API, e.g. used by "importer trigger",
+// listens. Requests must carry the token as bearer token. The API is disabled
+// if no address is set.
+type AdminConfig struct {
+	Address string `yaml:"address" env:"DOCUMENTER_ADMIN_ADDRESS"` // Listen address in the form host:port, e.g. 127.0.0.1:8081
+	Token   string `yaml:"token" env:"DOCUMENTER_ADMIN_TOKEN"`     // Token authenticating requests
+}
+
+// WebhookConfig defines a receiver of POST requests sent after a
+// documentation was created or its content changed. Payloads are signed with
+// HMAC-SHA256 if a secret is set.
+type WebhookConfig struct {
+	URL      string   `yaml:"url"`      // Endpoint receiving the events
+	Secret   string   `yaml:"secret"`   // Secret used to sign the payloads (optional)
+	Sections []string `yaml:"sections"` // Patterns of qualified section names to send events for, e.g. team/* (defaults to all)
+}
+
+// Matches reports whether the webhook receives events of the documentation
+// with the given key. Patterns use the syntax of path.Match and are matched
+// against the qualified name, see domain.DocumentationKey.String.
+func (w WebhookConfig) Matches(key domain.DocumentationKey) bool {
+	if len(w.Sections) == 0 {
+		return true
+	}
+
+	for _, pattern := range w.Sections {
+		if ok, _ := path.Match(pattern, key.String()); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DatabaseTLSConfig defines how connections to the database are secured.
+// File paths override the corresponding settings of the connection string.
+type DatabaseTLSConfig struct {
+	Mode     DatabaseTLSMode `yaml:"mode" env:"DOCUMENTER_DATABASE_TLS_MODE"`          // TLS mode (PostgreSQL sslmode)
+	CAFile   string          `yaml:"caFile" env:"DOCUMENTER_DATABASE_TLS_CA_FILE"`     // CA certificate to verify the server
+	CertFile string          `yaml:"certFile" env:"DOCUMENTER_DATABASE_TLS_CERT_FILE"` // Client certificate
+	KeyFile  string          `yaml:"keyFile" env:"DOCUMENTER_DATABASE_TLS_KEY_FILE"`   // Private key of the client certificate
+}
+
 // SectionType represents the different types of documentation sources supported.
 type SectionType int
 
 const (
 	// SectionTypeGit represents a Git repository as a documentation source
 	SectionTypeGit SectionType = iota
+	// SectionTypeDiscovery represents Git repositories discovered in an organization
+	SectionTypeDiscovery
 )
 
 // UnmarshalYAML implements yaml.Unmarshaler to parse SectionType from YAML.
@@ -53,13 +250,95 @@ func (t *SectionType) UnmarshalYAML(value *yaml.Node) error {
 	switch value.Value {
 	case "git":
 		*t = SectionTypeGit
+	case "discovery":
+		*t = SectionTypeDiscovery
 	default:
-		return fmt.Errorf("unknown section type: %s", value.Value)
+		return unmarshalError(value, "unknown section type: %s", value.Value)
 	}
 
 	return nil
 }
 
+// EnumValues returns the values accepted for SectionType in YAML.
+func (SectionType) EnumValues() []string {
+	return []string{"git", "discovery"}
+}
+
+// DiscoveryProvider represents the APIs of Git hosting services supported by
+// discovery sections.
+type DiscoveryProvider int
+
+const (
+	// DiscoveryProviderGitHub represents the GitHub REST API
+	DiscoveryProviderGitHub DiscoveryProvider = iota
+	// DiscoveryProviderGitea represents the Gitea (and Forgejo) API
+	DiscoveryProviderGitea
+	// DiscoveryProviderGitLab represents the GitLab REST API
+	DiscoveryProviderGitLab
+)
+
+// UnmarshalYAML implements yaml.Unmarshaler to parse DiscoveryProvider from YAML.
+// It converts string values from YAML into the appropriate DiscoveryProvider constant.
+func (p *DiscoveryProvider) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Value {
+	case "github":
+		*p = DiscoveryProviderGitHub
+	case "gitea":
+		*p = DiscoveryProviderGitea
+	case "gitlab":
+		*p = DiscoveryProviderGitLab
+	default:
+		return unmarshalError(value, "unknown discovery provider: %s", value.Value)
+	}
+
+	return nil
+}
+
+// EnumValues returns the values accepted for DiscoveryProvider in YAML.
+func (DiscoveryProvider) EnumValues() []string {
+	return []string{"github", "gitea", "gitlab"}
+}
+
+// PruneMode represents the different ways of handling documentations which are
+// no longer backed by a configured section.
+type PruneMode int
+
+const (
+	// PruneModeNone keeps documentations without a section untouched
+	PruneModeNone PruneMode = iota
+	// PruneModeDelete deletes documentations without a section, unless no
+	// section is active at all
+	PruneModeDelete
+	// PruneModeArchive moves documentations without a section to the archive
+	PruneModeArchive
+	// PruneModeStale marks documentations without a section as stale
+	PruneModeStale
+)
+
+// UnmarshalYAML implements yaml.Unmarshaler to parse PruneMode from YAML.
+// It converts string values from YAML into the appropriate PruneMode constant.
+func (m *PruneMode) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Value {
+	case "none":
+		*m = PruneModeNone
+	case "delete":
+		*m = PruneModeDelete
+	case "archive":
+		*m = PruneModeArchive
+	case "stale":
+		*m = PruneModeStale
+	default:
+		return unmarshalError(value, "unknown prune mode: %s", value.Value)
+	}
+
+	return nil
+}
+
+// EnumValues returns the values accepted for PruneMode in YAML.
+func (PruneMode) EnumValues() []string {
+	return []string{"none", "delete", "archive", "stale"}
+}
+
 // LoggingFormat represents the available formats for log output.
 type LoggingFormat int
 
@@ -79,8 +358,210 @@ func (t *LoggingFormat) UnmarshalYAML(value *yaml.Node) error {
 	case "json":
 		*t = LoggingFormatJSON
 	default:
-		return fmt.Errorf("unknown logging format: %s", value.Value)
+		return unmarshalError(value, "unknown logging format: %s", value.Value)
+	}
+
+	return nil
+}
+
+// EnumValues returns the values accepted for LoggingFormat in YAML.
+func (LoggingFormat) EnumValues() []string {
+	return []string{"text", "json"}
+}
+
+// DatabaseTLSMode represents the TLS modes of database connections. The modes
+// correspond to the sslmode parameter of PostgreSQL connection strings.
+type DatabaseTLSMode int
+
+const (
+	// DatabaseTLSModeDefault keeps the mode of the connection string
+	DatabaseTLSModeDefault DatabaseTLSMode = iota
+	// DatabaseTLSModeDisable disables TLS
+	DatabaseTLSModeDisable
+	// DatabaseTLSModeRequire requires TLS without verifying the server
+	DatabaseTLSModeRequire
+	// DatabaseTLSModeVerifyCA requires TLS and verifies the server certificate
+	DatabaseTLSModeVerifyCA
+	// DatabaseTLSModeVerifyFull requires TLS and verifies the server certificate and host name
+	DatabaseTLSModeVerifyFull
+)
+
+// UnmarshalYAML implements yaml.Unmarshaler to parse DatabaseTLSMode from YAML.
+// It converts string values from YAML into the appropriate DatabaseTLSMode constant.
+func (m *DatabaseTLSMode) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Value {
+	case "":
+		*m = DatabaseTLSModeDefault
+	case "disable":
+		*m = DatabaseTLSModeDisable
+	case "require":
+		*m = DatabaseTLSModeRequire
+	case "verify-ca":
+		*m = DatabaseTLSModeVerifyCA
+	case "verify-full":
+		*m = DatabaseTLSModeVerifyFull
+	default:
+		return unmarshalError(value, "unknown tls mode: %s", value.Value)
+	}
+
+	return nil
+}
+
+// EnumValues returns the values accepted for DatabaseTLSMode in YAML.
+func (DatabaseTLSMode) EnumValues() []string {
+	return []string{"disable", "require", "verify-ca", "verify-full"}
+}
+
+// String returns the sslmode parameter value of the mode.
+func (m DatabaseTLSMode) String() string {
+	switch m {
+	case DatabaseTLSModeDisable:
+		return "disable"
+	case DatabaseTLSModeRequire:
+		return "require"
+	case DatabaseTLSModeVerifyCA:
+		return "verify-ca"
+	case DatabaseTLSModeVerifyFull:
+		return "verify-full"
+	default:
+		return ""
+	}
+}
+
+// CompressionCodec represents the codecs used to compress stored contents.
+// Contents are read using the codec they were written with, so the codec can
+// be changed at any time.
+type CompressionCodec int
+
+const (
+	// CompressionCodecZstd compresses contents with Zstandard
+	CompressionCodecZstd CompressionCodec = iota
+	// CompressionCodecGzip compresses contents with gzip
+	CompressionCodecGzip
+	// CompressionCodecNone stores contents uncompressed
+	CompressionCodecNone
+)
+
+// UnmarshalYAML implements yaml.Unmarshaler to parse CompressionCodec from YAML.
+// It converts string values from YAML into the appropriate CompressionCodec constant.
+func (c *CompressionCodec) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Value {
+	case "zstd":
+		*c = CompressionCodecZstd
+	case "gzip":
+		*c = CompressionCodecGzip
+	case "none":
+		*c = CompressionCodecNone
+	default:
+		return unmarshalError(value, "unknown compression codec: %s", value.Value)
 	}
 
 	return nil
 }
+
+// EnumValues returns the values accepted for CompressionCodec in YAML.
+func (CompressionCodec) EnumValues() []string {
+	return []string{"zstd", "gzip", "none"}
+}
+
+// String returns the name of the codec.
+func (c CompressionCodec) String() string {
+	switch c {
+	case CompressionCodecGzip:
+		return "gzip"
+	case CompressionCodecNone:
+		return "none"
+	default:
+		return "zstd"
+	}
+}
+
+// unmarshalError creates a decoding error for the node. Type errors do not
+// abort decoding, so all invalid values of a document are reported at once.
+func unmarshalError(value *yaml.Node, format string, v ...any) error {
+	return &yaml.TypeError{Errors: []string{
+		fmt.Sprintf("line %d: %s", value.Line, fmt.Sprintf(format, v...)),
+	}}
+}
+
+// sectionDiff describes how the sections of two configurations differ.
+type sectionDiff struct {
+	Added     []SectionConfig
+	Removed   []SectionConfig
+	Changed   []SectionConfig
+	Unchanged []SectionConfig
+}
+
+// diffSections compares the sections by key. Changed and unchanged sections
+// contain the new section configuration.
+func diffSections(old, new []SectionConfig) sectionDiff {
+	oldByKey := make(map[domain.DocumentationKey]SectionConfig)
+	for _, section := range old {
+		oldByKey[section.Key()] = section
+	}
+
+	var diff sectionDiff
+	for _, section := range new {
+		prev, ok := oldByKey[section.Key()]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, section)
+		case !reflect.DeepEqual(prev, section):
+			diff.Changed = append(diff.Changed, section)
+		default:
+			diff.Unchanged = append(diff.Unchanged, section)
+		}
+		delete(oldByKey, section.Key())
+	}
+
+	for _, section := range old {
+		if _, ok := oldByKey[section.Key()]; ok {
+			diff.Removed = append(diff.Removed, section)
+		}
+	}
+
+	return diff
+}
+
+// sectionNames returns the names of the sections qualified by their
+// namespaces, see domain.DocumentationKey.String.
+func sectionNames(sections []SectionConfig) []string {
+	names := make([]string, 0, len(sections))
+	for _, section := range sections {
+		names = append(names, section.Key().String())
+	}
+
+	return names
+}
+
+func documentationKeys(sections []SectionConfig) []domain.DocumentationKey {
+	keys := make([]domain.DocumentationKey, 0, len(sections))
+	for _, section := range sections {
+		keys = append(keys, section.Key())
+	}
+
+	return keys
+}
+
+// hasDiscoverySections reports whether any section is a discovery section.
+func hasDiscoverySections(sections []SectionConfig) bool {
+	for _, section := range sections {
+		if section.Type == SectionTypeDiscovery {
+			return true
+		}
+	}
+
+	return false
+}
+
+// staticSections returns the sections which are not discovery sections.
+func staticSections(sections []SectionConfig) []SectionConfig {
+	static := make([]SectionConfig, 0, len(sections))
+	for _, section := range sections {
+		if section.Type != SectionTypeDiscovery {
+			static = append(static, section)
+		}
+	}
+
+	return static
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// applyEnvOverrides sets all fields of the struct pointed to by v which have
+// an env tag to the value of the named environment variable, if it is set.
+// Values are decoded like YAML scalars, so they accept the same formats as
+// the configuration file.
+func applyEnvOverrides(v any, lookupEnv func(string) (string, bool)) []error {
+	return applyEnvOverridesValue(reflect.ValueOf(v).Elem(), lookupEnv)
+}
+
+func applyEnvOverridesValue(v reflect.Value, lookupEnv func(string) (string, bool)) []error {
+	var errs []error
+
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Tag.Get("env")
+		if len(name) == 0 {
+			if field.Type.Kind() == reflect.Struct {
+				errs = append(errs, applyEnvOverridesValue(v.Field(i), lookupEnv)...)
+			}
+			continue
+		}
+
+		value, ok := lookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		node := yaml.Node{Kind: yaml.ScalarNode, Value: value}
+		if err := node.Decode(v.Field(i).Addr().Interface()); err != nil {
+			errs = append(errs, fmt.Errorf("environment variable %s: %w", name, unwrapTypeError(err)))
+		}
+	}
+
+	return errs
+}
+
+// unwrapTypeError removes the line information from YAML type errors, which
+// is meaningless for values not read from a file.
+func unwrapTypeError(err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+
+	var errs []error
+	for _, message := range typeErr.Errors {
+		if m := lineError.FindStringSubmatch(message); m != nil {
+			message = m[2]
+		}
+		errs = append(errs, errors.New(message))
+	}
+
+	return errors.Join(errs...)
+}
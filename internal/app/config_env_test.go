@@ -0,0 +1,54 @@
+package app_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flohansen/documenter/internal/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig_EnvOverrides(t *testing.T) {
+	t.Run("should override database config by environment", func(t *testing.T) {
+		// assign
+		t.Setenv("DOCUMENTER_DATABASE_DSN", "postgres://user:secret@db:5432/docs")
+		t.Setenv("DOCUMENTER_DATABASE_MAX_CONNS", "20")
+		t.Setenv("DOCUMENTER_DATABASE_CONNECT_TIMEOUT", "3s")
+		t.Setenv("DOCUMENTER_DATABASE_TLS_MODE", "verify-full")
+		t.Setenv("DOCUMENTER_DATABASE_COMPRESSION", "gzip")
+		b := []byte(strings.Join([]string{
+			"database:",
+			"  dsn: postgres://localhost:5432/postgres",
+			"  maxConns: 4",
+			"  minConns: 2",
+		}, "\n"))
+
+		// act
+		config, err := app.ParseConfig(b)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, app.DatabaseConfig{
+			DSN:            "postgres://user:secret@db:5432/docs",
+			MaxConns:       20,
+			MinConns:       2,
+			ConnectTimeout: 3 * time.Second,
+			TLS: app.DatabaseTLSConfig{
+				Mode: app.DatabaseTLSModeVerifyFull,
+			},
+			Compression: app.CompressionCodecGzip,
+		}, config.Database)
+	})
+
+	t.Run("should return error for invalid environment value", func(t *testing.T) {
+		// assign
+		t.Setenv("DOCUMENTER_DATABASE_MAX_CONNS", "many")
+
+		// act
+		_, err := app.ParseConfig([]byte{})
+
+		// assert
+		assert.ErrorContains(t, err, "environment variable DOCUMENTER_DATABASE_MAX_CONNS")
+	})
+}
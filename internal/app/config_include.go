@@ -0,0 +1,167 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SectionsFile is the format of files included by the configuration. Each
+// included file contributes its sections to DocsConfig.Sections.
+type SectionsFile struct {
+	Namespace string          `yaml:"namespace"` // Namespace of all sections which do not set one
+	Sections  []SectionConfig `yaml:"sections"`  // List of documentation sections
+}
+
+// sections returns the sections of the file with the file's namespace
+// applied.
+func (f SectionsFile) sections() []SectionConfig {
+	sections := make([]SectionConfig, 0, len(f.Sections))
+	for _, section := range f.Sections {
+		if len(section.Namespace) == 0 {
+			section.Namespace = f.Namespace
+		}
+
+		sections = append(sections, section)
+	}
+
+	return sections
+}
+
+// configFile is a single file making up the configuration.
+type configFile struct {
+	Name    string
+	Content []byte
+}
+
+// configFileSet contains the configuration file followed by all files it
+// includes, and the directories which may contain (future) includes.
+type configFileSet struct {
+	Files []configFile
+	Dirs  []string
+}
+
+// Equal reports whether both sets consist of the same files and contents.
+func (s configFileSet) Equal(other configFileSet) bool {
+	if len(s.Files) != len(other.Files) {
+		return false
+	}
+
+	for i := range s.Files {
+		if s.Files[i].Name != other.Files[i].Name || !bytes.Equal(s.Files[i].Content, other.Files[i].Content) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// readConfigFiles reads the configuration file with the given name and all
+// files matched by its include patterns. Relative patterns are resolved
+// against the directory of the configuration file. If the include patterns
+// cannot be parsed, only the configuration file is returned, so the problem
+// is reported when the configuration itself is parsed.
+func readConfigFiles(name string) (configFileSet, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return configFileSet{}, fmt.Errorf("could not read file: %w", err)
+	}
+
+	dir := filepath.Dir(name)
+	set := configFileSet{
+		Files: []configFile{{Name: name, Content: b}},
+		Dirs:  []string{dir},
+	}
+
+	var includes struct {
+		Include []string `yaml:"include"`
+	}
+	if root, problems := decodeConfigFile(set.Files[0], &includes); root == nil || len(problems) > 0 {
+		return set, nil
+	}
+
+	seen := map[string]bool{filepath.Clean(name): true}
+	for _, pattern := range includes.Include {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return configFileSet{}, fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+		}
+
+		set.Dirs = append(set.Dirs, filepath.Dir(pattern))
+		for _, match := range matches {
+			if seen[filepath.Clean(match)] {
+				continue
+			}
+			seen[filepath.Clean(match)] = true
+
+			b, err := os.ReadFile(match)
+			if err != nil {
+				return configFileSet{}, fmt.Errorf("could not read included file: %w", err)
+			}
+
+			set.Files = append(set.Files, configFile{Name: match, Content: b})
+		}
+	}
+
+	return set, nil
+}
+
+// parseConfigFiles parses the configuration file and merges the sections of
+// all included files into DocsConfig.Sections.
+func parseConfigFiles(set configFileSet) (Config, error) {
+	config, err := ParseConfig(set.Files[0].Content)
+	if err != nil {
+		return Config{}, err
+	}
+
+	for _, file := range set.Files[1:] {
+		var sections SectionsFile
+		if _, problems := decodeConfigFile(file, &sections); len(problems) > 0 {
+			var errs []error
+			for _, problem := range problems {
+				errs = append(errs, errors.New(problem.String()))
+			}
+
+			return Config{}, fmt.Errorf("%s: %w", file.Name, errors.Join(errs...))
+		}
+
+		config.Docs.Sections = append(config.Docs.Sections, sections.sections()...)
+	}
+
+	return config, nil
+}
+
+// decodeConfigFile parses the file, expands its environment variables and
+// decodes it into out. All problems found are returned. The root node is nil
+// if the file is not valid YAML.
+func decodeConfigFile(file configFile, out any) (*yaml.Node, []ConfigProblem) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(file.Content, &root); err != nil {
+		return nil, decodeProblems(file.Name, err)
+	}
+
+	if errs := interpolateConfig(&root, os.LookupEnv); len(errs) > 0 {
+		var problems []ConfigProblem
+		for _, err := range errs {
+			problems = append(problems, decodeProblems(file.Name, err)...)
+		}
+
+		return &root, problems
+	}
+
+	// Type errors leave the affected fields empty but decode the rest of the
+	// document, so the remaining fields can be validated as well.
+	if err := root.Decode(out); err != nil {
+		return &root, decodeProblems(file.Name, err)
+	}
+
+	return &root, nil
+}
@@ -0,0 +1,163 @@
+package app_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flohansen/documenter/internal/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadConfig_Include(t *testing.T) {
+	t.Run("should merge sections of included files", func(t *testing.T) {
+		// assign
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "documenter.config.yaml"), strings.Join([]string{
+			"include:",
+			"  - conf.d/*.yaml",
+			"docs:",
+			"  sections:",
+			"    - name: A",
+			"      type: git",
+			"      url: https://some.url.com/a",
+		}, "\n"))
+		mkdir(t, filepath.Join(dir, "conf.d"))
+		writeFile(t, filepath.Join(dir, "conf.d", "b.yaml"), strings.Join([]string{
+			"sections:",
+			"  - name: B",
+			"    type: git",
+			"    url: https://some.url.com/b",
+		}, "\n"))
+		writeFile(t, filepath.Join(dir, "conf.d", "c.yaml"), strings.Join([]string{
+			"sections:",
+			"  - name: C",
+			"    type: git",
+			"    url: https://some.url.com/c",
+		}, "\n"))
+
+		// act
+		config, err := app.ReadConfig(filepath.Join(dir, "documenter.config.yaml"))
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []app.SectionConfig{
+			{Name: "A", Type: app.SectionTypeGit, URL: "https://some.url.com/a"},
+			{Name: "B", Type: app.SectionTypeGit, URL: "https://some.url.com/b"},
+			{Name: "C", Type: app.SectionTypeGit, URL: "https://some.url.com/c"},
+		}, config.Docs.Sections)
+	})
+
+	t.Run("should apply namespace of included files", func(t *testing.T) {
+		// assign
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "documenter.config.yaml"), strings.Join([]string{
+			"include:",
+			"  - conf.d/*.yaml",
+		}, "\n"))
+		mkdir(t, filepath.Join(dir, "conf.d"))
+		writeFile(t, filepath.Join(dir, "conf.d", "team.yaml"), strings.Join([]string{
+			"namespace: team",
+			"sections:",
+			"  - name: A",
+			"    type: git",
+			"    url: https://some.url.com/a",
+			"  - namespace: other",
+			"    name: A",
+			"    type: git",
+			"    url: https://some.url.com/a",
+		}, "\n"))
+
+		// act
+		config, err := app.ReadConfig(filepath.Join(dir, "documenter.config.yaml"))
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []app.SectionConfig{
+			{Namespace: "team", Name: "A", Type: app.SectionTypeGit, URL: "https://some.url.com/a"},
+			{Namespace: "other", Name: "A", Type: app.SectionTypeGit, URL: "https://some.url.com/a"},
+		}, config.Docs.Sections)
+	})
+
+	t.Run("should return error with file name if included file is invalid", func(t *testing.T) {
+		// assign
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "documenter.config.yaml"), "include:\n  - conf.d/*.yaml\n")
+		mkdir(t, filepath.Join(dir, "conf.d"))
+		writeFile(t, filepath.Join(dir, "conf.d", "b.yaml"), "sections:\n  - name: B\n    type: anything\n")
+
+		// act
+		_, err := app.ReadConfig(filepath.Join(dir, "documenter.config.yaml"))
+
+		// assert
+		assert.ErrorContains(t, err, filepath.Join(dir, "conf.d", "b.yaml"))
+		assert.ErrorContains(t, err, "line 3: unknown section type: anything")
+	})
+}
+
+func TestValidateConfigFile_Include(t *testing.T) {
+	t.Run("should report duplicate names across files", func(t *testing.T) {
+		// assign
+		dir := t.TempDir()
+		main := filepath.Join(dir, "documenter.config.yaml")
+		included := filepath.Join(dir, "conf.d", "b.yaml")
+		writeFile(t, main, strings.Join([]string{
+			"include:",
+			"  - conf.d/*.yaml",
+			"scraping:",
+			"  interval: 1m",
+			"docs:",
+			"  sections:",
+			"    - name: A",
+			"      type: git",
+			"      url: https://some.url.com/a",
+		}, "\n"))
+		mkdir(t, filepath.Join(dir, "conf.d"))
+		writeFile(t, included, strings.Join([]string{
+			"sections:",
+			"  - name: B",
+			"    type: git",
+			"    url: https://some.url.com/b",
+			"  - name: A",
+			"    type: git",
+		}, "\n"))
+
+		// act
+		problems, err := app.ValidateConfigFile(main)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []app.ConfigProblem{
+			{
+				File:    included,
+				Path:    "sections[1].name",
+				Line:    5,
+				Message: "duplicate name \"A\", already used by docs.sections[0] (" + main + ":7)",
+			},
+			{
+				File:    included,
+				Path:    "sections[1].url",
+				Line:    5,
+				Message: "url is required",
+			},
+		}, stripUnexported(problems))
+	})
+}
+
+// stripUnexported returns copies of the problems containing only the exported
+// fields, so they can be compared.
+func stripUnexported(problems []app.ConfigProblem) []app.ConfigProblem {
+	var stripped []app.ConfigProblem
+	for _, p := range problems {
+		stripped = append(stripped, app.ConfigProblem{File: p.File, Path: p.Path, Line: p.Line, Message: p.Message})
+	}
+
+	return stripped
+}
+
+func mkdir(t *testing.T, name string) {
+	if err := os.MkdirAll(name, 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
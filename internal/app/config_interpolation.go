@@ -0,0 +1,119 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileKeySuffix marks keys whose value is read from the file at the given
+// path, e.g. "url_file: /run/secrets/url" sets "url" to the file's content.
+const fileKeySuffix = "_file"
+
+// envVariable matches "$$" (an escaped dollar sign), "${VAR}" and
+// "${VAR:-default}".
+var envVariable = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// interpolateConfig expands environment variables in all scalar values of the
+// node tree and resolves keys with the "_file" suffix by reading the
+// referenced files. Every problem is reported as its own error including the
+// line of the affected node.
+func interpolateConfig(node *yaml.Node, lookupEnv func(string) (string, bool)) []error {
+	var errs []error
+
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			errs = append(errs, interpolateConfig(child, lookupEnv)...)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			errs = append(errs, interpolateConfig(node.Content[i+1], lookupEnv)...)
+		}
+		errs = append(errs, resolveFileKeys(node)...)
+	case yaml.ScalarNode:
+		if err := expandScalar(node, lookupEnv); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// expandScalar replaces environment variables within the scalar node.
+func expandScalar(node *yaml.Node, lookupEnv func(string) (string, bool)) error {
+	if !strings.Contains(node.Value, "$") {
+		return nil
+	}
+
+	var missing []string
+	value := envVariable.ReplaceAllStringFunc(node.Value, func(match string) string {
+		if match == "$$" {
+			return "$"
+		}
+
+		m := envVariable.FindStringSubmatch(match)
+		if v, ok := lookupEnv(m[1]); ok && (len(v) > 0 || len(m[2]) == 0) {
+			return v
+		}
+		if len(m[2]) > 0 {
+			return m[3]
+		}
+
+		missing = append(missing, m[1])
+		return ""
+	})
+
+	if len(missing) > 0 {
+		return fmt.Errorf("line %d: environment variable %s is not set", node.Line, strings.Join(missing, ", "))
+	}
+
+	setScalar(node, value)
+	return nil
+}
+
+// resolveFileKeys replaces every "<key>_file: <path>" pair of the mapping
+// node with "<key>: <content of path>".
+func resolveFileKeys(node *yaml.Node) []error {
+	keys := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keys[node.Content[i].Value] = true
+	}
+
+	var errs []error
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if !strings.HasSuffix(key.Value, fileKeySuffix) || value.Kind != yaml.ScalarNode {
+			continue
+		}
+
+		name := strings.TrimSuffix(key.Value, fileKeySuffix)
+		if keys[name] {
+			errs = append(errs, fmt.Errorf("line %d: %s and %s must not be set both", key.Line, name, key.Value))
+			continue
+		}
+
+		b, err := os.ReadFile(value.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: could not read %s: %v", value.Line, key.Value, err))
+			continue
+		}
+
+		key.Value = name
+		setScalar(value, strings.TrimRight(string(b), "\r\n"))
+	}
+
+	return errs
+}
+
+// setScalar sets the value of the scalar node. The tag of plain scalars is
+// reset, so the new value is resolved (e.g. as bool or int) when decoding.
+func setScalar(node *yaml.Node, value string) {
+	node.Value = value
+	if node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+		node.Tag = ""
+	}
+}
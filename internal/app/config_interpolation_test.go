@@ -0,0 +1,123 @@
+package app_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flohansen/documenter/internal/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig_Interpolation(t *testing.T) {
+	t.Run("should expand environment variables", func(t *testing.T) {
+		// assign
+		t.Setenv("DOCUMENTER_URL", "https://some.url.com/repo")
+		t.Setenv("DOCUMENTER_TRACING", "true")
+		b := []byte(strings.Join([]string{
+			"scraping:",
+			"  interval: ${DOCUMENTER_INTERVAL:-5m}",
+			"docs:",
+			"  sections:",
+			"    - name: Test",
+			"      type: git",
+			"      url: ${DOCUMENTER_URL}",
+			"tracing:",
+			"  enabled: ${DOCUMENTER_TRACING}",
+			"  serviceName: $${literal}",
+		}, "\n"))
+
+		// act
+		config, err := app.ParseConfig(b)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, 5*time.Minute, config.Scraping.Interval)
+		assert.Equal(t, "https://some.url.com/repo", config.Docs.Sections[0].URL)
+		assert.True(t, config.Tracing.Enabled)
+		assert.Equal(t, "${literal}", config.Tracing.ServiceName)
+	})
+
+	t.Run("should use default if environment variable is empty", func(t *testing.T) {
+		// assign
+		t.Setenv("DOCUMENTER_ENDPOINT", "")
+		b := []byte("tracing:\n  endpoint: ${DOCUMENTER_ENDPOINT:-localhost:4318}\n")
+
+		// act
+		config, err := app.ParseConfig(b)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "localhost:4318", config.Tracing.Endpoint)
+	})
+
+	t.Run("should return error if environment variable is not set", func(t *testing.T) {
+		// assign
+		b := []byte("tracing:\n  endpoint: ${DOCUMENTER_NOT_SET}\n")
+
+		// act
+		_, err := app.ParseConfig(b)
+
+		// assert
+		assert.ErrorContains(t, err, "line 2: environment variable DOCUMENTER_NOT_SET is not set")
+	})
+
+	t.Run("should read values from files", func(t *testing.T) {
+		// assign
+		secret := filepath.Join(t.TempDir(), "url")
+		if err := os.WriteFile(secret, []byte("https://some.url.com/repo\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("DOCUMENTER_SECRET", secret)
+
+		b := []byte(strings.Join([]string{
+			"docs:",
+			"  sections:",
+			"    - name: Test",
+			"      type: git",
+			"      url_file: ${DOCUMENTER_SECRET}",
+		}, "\n"))
+
+		// act
+		config, err := app.ParseConfig(b)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "https://some.url.com/repo", config.Docs.Sections[0].URL)
+	})
+
+	t.Run("should return error if value and file are both set", func(t *testing.T) {
+		// assign
+		b := []byte(strings.Join([]string{
+			"docs:",
+			"  sections:",
+			"    - name: Test",
+			"      url: https://some.url.com/repo",
+			"      url_file: /run/secrets/url",
+		}, "\n"))
+
+		// act
+		_, err := app.ParseConfig(b)
+
+		// assert
+		assert.ErrorContains(t, err, "line 5: url and url_file must not be set both")
+	})
+
+	t.Run("should return error if file is not readable", func(t *testing.T) {
+		// assign
+		b := []byte(strings.Join([]string{
+			"docs:",
+			"  sections:",
+			"    - name: Test",
+			"      url_file: /does/not/exist",
+		}, "\n"))
+
+		// act
+		_, err := app.ParseConfig(b)
+
+		// assert
+		assert.ErrorContains(t, err, "line 4: could not read url_file")
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/flohansen/documenter/internal/app"
+	"github.com/flohansen/documenter/internal/domain"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
 )
@@ -111,4 +112,61 @@ func TestConfig_UnmarshalYAML(t *testing.T) {
 			assert.Error(t, err)
 		})
 	})
+
+	t.Run("prune mode", func(t *testing.T) {
+		t.Run("should unmarshal prune modes", func(t *testing.T) {
+			for value, mode := range map[string]app.PruneMode{
+				"none":    app.PruneModeNone,
+				"delete":  app.PruneModeDelete,
+				"archive": app.PruneModeArchive,
+				"stale":   app.PruneModeStale,
+			} {
+				// assign
+				b := []byte(strings.Join([]string{
+					"docs:",
+					"  prune:",
+					"    mode: " + value,
+				}, "\n"))
+
+				// act
+				var config app.Config
+				err := yaml.Unmarshal(b, &config)
+
+				// assert
+				assert.NoError(t, err)
+				assert.Equal(t, mode, config.Docs.Prune.Mode)
+			}
+		})
+
+		t.Run("should return error when prune mode is unknown", func(t *testing.T) {
+			// assign
+			b := []byte(strings.Join([]string{
+				"docs:",
+				"  prune:",
+				"    mode: anything",
+			}, "\n"))
+
+			// act
+			var config app.Config
+			err := yaml.Unmarshal(b, &config)
+
+			// assert
+			assert.Error(t, err)
+		})
+	})
+}
+
+func TestWebhookConfig_Matches(t *testing.T) {
+	t.Run("should distinguish namespaces from names containing slashes", func(t *testing.T) {
+		// assign
+		hook := app.WebhookConfig{Sections: []string{"team/*"}}
+
+		// act
+		namespaced := hook.Matches(domain.DocumentationKey{Namespace: "team", Name: "docs"})
+		discovered := hook.Matches(domain.DocumentationKey{Namespace: domain.DefaultNamespace, Name: "team/docs"})
+
+		// assert
+		assert.True(t, namespaced)
+		assert.False(t, discovered)
+	})
 }
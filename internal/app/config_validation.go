@@ -0,0 +1,496 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flohansen/documenter/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// MinScrapingInterval is the smallest recommended scraping interval. Shorter
+// intervals are accepted, but reported as warning by the configuration
+// validation.
+const MinScrapingInterval = 10 * time.Second
+
+var (
+	// scpLikeURL matches Git URLs in the scp-like syntax, e.g. git@host:repo.git.
+	scpLikeURL = regexp.MustCompile(`^[\w.-]+@[\w.-]+:[^/].*$`)
+	// namespacePattern matches valid namespaces. Namespaces must not contain
+	// "/", which separates them from names in qualified names.
+	namespacePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	// lineError matches the line prefix of YAML decoding errors.
+	lineError = regexp.MustCompile(`line (\d+): (.*)$`)
+)
+
+// ConfigProblem describes a single problem found while validating a
+// configuration.
+type ConfigProblem struct {
+	File    string // File containing the problem (empty if unknown)
+	Path    string // Path to the affected field, e.g. docs.sections[0].url
+	Line    int    // Line in the configuration file (0 if unknown)
+	Message string // Description of the problem
+	Warning bool   // Whether the problem is a warning, which does not fail validation
+
+	keys []any
+	// related points to another field involved in the problem. The message
+	// is rendered from relatedFormat with the location of the related field.
+	related       []any
+	relatedFormat string
+}
+
+// String returns the problem in the form "line: path: message". Warnings are
+// marked by a "warning: " prefix of the message.
+func (p ConfigProblem) String() string {
+	var sb strings.Builder
+	if p.Line > 0 {
+		fmt.Fprintf(&sb, "line %d: ", p.Line)
+	}
+	if len(p.Path) > 0 {
+		fmt.Fprintf(&sb, "%s: ", p.Path)
+	}
+	if p.Warning {
+		sb.WriteString("warning: ")
+	}
+	sb.WriteString(p.Message)
+
+	return sb.String()
+}
+
+// Validate checks the configuration for problems which would prevent the
+// importer from running the configured sections. All problems except
+// warnings are joined into the returned error.
+func (c Config) Validate() error {
+	var errs []error
+	for _, problem := range c.Problems() {
+		if problem.Warning {
+			continue
+		}
+		errs = append(errs, errors.New(problem.String()))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Warnings returns the problems of the configuration which are warnings and
+// do not fail validation.
+func (c Config) Warnings() []ConfigProblem {
+	var warnings []ConfigProblem
+	for _, problem := range c.Problems() {
+		if problem.Warning {
+			warnings = append(warnings, problem)
+		}
+	}
+
+	return warnings
+}
+
+// Problems returns all problems of the configuration. Line numbers are not
+// known and left empty, use ValidateConfig to validate an encoded
+// configuration including line numbers.
+func (c Config) Problems() []ConfigProblem {
+	var problems []ConfigProblem
+	report := func(message string, keys ...any) {
+		problems = append(problems, newConfigProblem(message, keys...))
+	}
+	warn := func(message string, keys ...any) {
+		problem := newConfigProblem(message, keys...)
+		problem.Warning = true
+		problems = append(problems, problem)
+	}
+
+	if c.Scraping.Interval <= 0 {
+		report("interval is required", "scraping", "interval")
+	} else if c.Scraping.Interval < MinScrapingInterval {
+		warn(fmt.Sprintf("interval is shorter than the recommended minimum of %s", MinScrapingInterval), "scraping", "interval")
+	}
+
+	keys := make(map[domain.DocumentationKey]int)
+	for i, section := range c.Docs.Sections {
+		if len(section.Namespace) > 0 && !namespacePattern.MatchString(section.Namespace) {
+			report(fmt.Sprintf("invalid namespace %q, must consist of letters, digits, '.', '_' and '-'", section.Namespace), "docs", "sections", i, "namespace")
+		}
+
+		if len(section.Name) == 0 {
+			report("name is required", "docs", "sections", i, "name")
+		} else if first, ok := keys[section.Key()]; ok {
+			name := section.Key().String()
+			problem := newConfigProblem(fmt.Sprintf("duplicate name %q, already used by docs.sections[%d]", name, first), "docs", "sections", i, "name")
+			problem.related = []any{"docs", "sections", first}
+			problem.relatedFormat = fmt.Sprintf("duplicate name %q, already used by %%s", name)
+			problems = append(problems, problem)
+		} else {
+			keys[section.Key()] = i
+		}
+
+		if len(section.URL) == 0 {
+			report("url is required", "docs", "sections", i, "url")
+		} else if section.Type == SectionTypeGit && !isGitURL(section.URL) {
+			report(fmt.Sprintf("invalid git url %q", section.URL), "docs", "sections", i, "url")
+		} else if section.Type == SectionTypeDiscovery && !isHTTPURL(section.URL) {
+			report(fmt.Sprintf("invalid api url %q", section.URL), "docs", "sections", i, "url")
+		}
+
+		for j, tag := range section.Tags {
+			if len(strings.TrimSpace(tag)) == 0 {
+				report("tag must not be empty", "docs", "sections", i, "tags", j)
+			}
+		}
+
+		if len(section.Category) > 0 && slices.Contains(strings.Split(section.Category, "/"), "") {
+			report(fmt.Sprintf("invalid category path %q", section.Category), "docs", "sections", i, "category")
+		}
+
+		if section.Type == SectionTypeDiscovery {
+			if len(section.Discovery.Organization) == 0 {
+				report("organization is required", "docs", "sections", i, "discovery", "organization")
+			}
+			if _, err := regexp.Compile(section.Discovery.NamePattern); err != nil {
+				report(fmt.Sprintf("invalid name pattern: %v", err), "docs", "sections", i, "discovery", "namePattern")
+			}
+		}
+
+		if len(section.SSHKey) > 0 {
+			if _, err := os.ReadFile(section.SSHKey); err != nil {
+				report(fmt.Sprintf("ssh key is not readable: %v", err), "docs", "sections", i, "sshKey")
+			}
+		}
+	}
+
+	if c.Tracing.Enabled && len(c.Tracing.Endpoint) == 0 {
+		report("endpoint is required when tracing is enabled", "tracing", "endpoint")
+	}
+
+	if c.Database.MinConns < 0 {
+		report("minConns must not be negative", "database", "minConns")
+	}
+	if c.Database.MaxConns < 0 {
+		report("maxConns must not be negative", "database", "maxConns")
+	} else if c.Database.MaxConns > 0 && c.Database.MinConns > c.Database.MaxConns {
+		report("minConns must not be greater than maxConns", "database", "minConns")
+	}
+	if c.Database.ConnectTimeout < 0 {
+		report("connectTimeout must not be negative", "database", "connectTimeout")
+	}
+	if (len(c.Database.TLS.CertFile) > 0) != (len(c.Database.TLS.KeyFile) > 0) {
+		report("certFile and keyFile must be set together", "database", "tls")
+	}
+	for _, file := range []struct{ key, name string }{
+		{"caFile", c.Database.TLS.CAFile},
+		{"certFile", c.Database.TLS.CertFile},
+		{"keyFile", c.Database.TLS.KeyFile},
+	} {
+		if len(file.name) == 0 {
+			continue
+		}
+		if _, err := os.ReadFile(file.name); err != nil {
+			report(fmt.Sprintf("file is not readable: %v", err), "database", "tls", file.key)
+		}
+	}
+
+	if len(c.ObjectStorage.Endpoint) > 0 {
+		if strings.Contains(c.ObjectStorage.Endpoint, "://") {
+			report("endpoint must be in the form host:port", "objectStorage", "endpoint")
+		}
+		if len(c.ObjectStorage.Bucket) == 0 {
+			report("bucket is required when object storage is enabled", "objectStorage", "bucket")
+		}
+		if (len(c.ObjectStorage.AccessKey) > 0) != (len(c.ObjectStorage.SecretKey) > 0) {
+			report("accessKey and secretKey must be set together", "objectStorage")
+		}
+	}
+
+	if len(c.Metrics.Address) > 0 {
+		if _, _, err := net.SplitHostPort(c.Metrics.Address); err != nil {
+			report("address must be in the form host:port", "metrics", "address")
+		}
+	}
+	if len(c.Metrics.Path) > 0 && !strings.HasPrefix(c.Metrics.Path, "/") {
+		report("path must start with /", "metrics", "path")
+	}
+
+	if len(c.Push.Address) > 0 {
+		if _, _, err := net.SplitHostPort(c.Push.Address); err != nil {
+			report("address must be in the form host:port", "push", "address")
+		}
+		if len(c.Push.Secret) == 0 {
+			report("secret is required when the push endpoint is enabled", "push", "secret")
+		}
+	}
+	if len(c.Push.Path) > 0 && !strings.HasPrefix(c.Push.Path, "/") {
+		report("path must start with /", "push", "path")
+	}
+
+	if len(c.Admin.Address) > 0 {
+		if _, _, err := net.SplitHostPort(c.Admin.Address); err != nil {
+			report("address must be in the form host:port", "admin", "address")
+		}
+		if len(c.Admin.Token) == 0 {
+			report("token is required when the admin API is enabled", "admin", "token")
+		}
+	}
+
+	for i, hook := range c.Webhooks {
+		if len(hook.URL) == 0 {
+			report("url is required", "webhooks", i, "url")
+		} else if !isHTTPURL(hook.URL) {
+			report(fmt.Sprintf("invalid webhook url %q", hook.URL), "webhooks", i, "url")
+		}
+		for j, pattern := range hook.Sections {
+			if _, err := path.Match(pattern, ""); err != nil {
+				report(fmt.Sprintf("invalid section pattern %q", pattern), "webhooks", i, "sections", j)
+			}
+		}
+	}
+
+	return problems
+}
+
+// ValidateConfig parses and validates the YAML encoded configuration. It
+// returns all problems found including the line numbers of the affected
+// fields. Included files are not resolved, use ValidateConfigFile instead.
+func ValidateConfig(b []byte) []ConfigProblem {
+	return validateConfigFiles(configFileSet{
+		Files: []configFile{{Content: b}},
+	})
+}
+
+// ValidateConfigFile reads and validates the configuration file with the
+// given name and all files it includes. See ValidateConfig.
+func ValidateConfigFile(name string) ([]ConfigProblem, error) {
+	set, err := readConfigFiles(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return validateConfigFiles(set), nil
+}
+
+// sectionSource describes where a section of the merged configuration is
+// defined.
+type sectionSource struct {
+	file string
+	node *yaml.Node
+	keys []any
+}
+
+// validateConfigFiles decodes and validates the configuration files. Problems
+// of sections are reported at the file and line the section is defined in.
+func validateConfigFiles(set configFileSet) []ConfigProblem {
+	main := set.Files[0]
+
+	var config Config
+	root, problems := decodeConfigFile(main, &config)
+	if root == nil {
+		return problems
+	}
+
+	for _, err := range applyEnvOverrides(&config, os.LookupEnv) {
+		problems = append(problems, ConfigProblem{Message: err.Error()})
+	}
+
+	var sources []sectionSource
+	for i := range config.Docs.Sections {
+		keys := []any{"docs", "sections", i}
+		sources = append(sources, sectionSource{file: main.Name, node: locateNode(root, keys), keys: keys})
+	}
+
+	for _, file := range set.Files[1:] {
+		var sections SectionsFile
+		fileRoot, fileProblems := decodeConfigFile(file, &sections)
+		problems = append(problems, fileProblems...)
+		if fileRoot == nil {
+			continue
+		}
+
+		for i := range sections.Sections {
+			keys := []any{"sections", i}
+			sources = append(sources, sectionSource{file: file.Name, node: locateNode(fileRoot, keys), keys: keys})
+		}
+		config.Docs.Sections = append(config.Docs.Sections, sections.sections()...)
+	}
+
+	for _, problem := range config.Problems() {
+		problem.File = main.Name
+		node := locateNode(root, problem.keys)
+
+		if i, rest, ok := sectionKeys(problem.keys); ok {
+			source := sources[i]
+			problem.File = source.file
+			problem.Path = newConfigProblem("", append(source.keys, rest...)...).Path
+
+			node = source.node
+			if n := locateNode(source.node, rest); n != nil && len(rest) > 0 {
+				node = n
+			}
+		}
+		if i, _, ok := sectionKeys(problem.related); ok && len(set.Files) > 1 {
+			source := sources[i]
+			location := newConfigProblem("", source.keys...).Path
+			if source.node != nil {
+				location = fmt.Sprintf("%s (%s:%d)", location, source.file, source.node.Line)
+			}
+			problem.Message = fmt.Sprintf(problem.relatedFormat, location)
+		}
+
+		if node != nil {
+			problem.Line = node.Line
+		}
+
+		problems = append(problems, problem)
+	}
+
+	return problems
+}
+
+// sectionKeys splits keys pointing into docs.sections into the index of the
+// section and the keys within the section.
+func sectionKeys(keys []any) (int, []any, bool) {
+	if len(keys) < 3 || keys[0] != "docs" || keys[1] != "sections" {
+		return 0, nil, false
+	}
+
+	i, ok := keys[2].(int)
+	return i, keys[3:], ok
+}
+
+func newConfigProblem(message string, keys ...any) ConfigProblem {
+	var path strings.Builder
+	for _, key := range keys {
+		switch k := key.(type) {
+		case int:
+			fmt.Fprintf(&path, "[%d]", k)
+		default:
+			if path.Len() > 0 {
+				path.WriteByte('.')
+			}
+			fmt.Fprint(&path, k)
+		}
+	}
+
+	return ConfigProblem{
+		Path:    path.String(),
+		Message: message,
+		keys:    keys,
+	}
+}
+
+// decodeProblems converts YAML decoding errors into problems. Decoding errors
+// carry their line number within the message.
+func decodeProblems(file string, err error) []ConfigProblem {
+	var messages []string
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	} else {
+		messages = []string{err.Error()}
+	}
+
+	problems := make([]ConfigProblem, 0, len(messages))
+	for _, message := range messages {
+		problem := ConfigProblem{File: file, Message: message}
+		if m := lineError.FindStringSubmatch(message); m != nil {
+			problem.Line, _ = strconv.Atoi(m[1])
+			problem.Message = m[2]
+		}
+
+		problems = append(problems, problem)
+	}
+
+	return problems
+}
+
+// locateNode follows the keys (mapping keys or sequence indexes) starting at
+// the node. If a key cannot be found, the deepest node found so far is
+// returned, so problems with missing fields point to their parent. It
+// returns nil if not even the first key can be found.
+func locateNode(node *yaml.Node, keys []any) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	for depth, key := range keys {
+		var next *yaml.Node
+		switch k := key.(type) {
+		case int:
+			if node.Kind == yaml.SequenceNode && k < len(node.Content) {
+				next = node.Content[k]
+			}
+		case string:
+			if node.Kind == yaml.MappingNode {
+				for i := 0; i+1 < len(node.Content); i += 2 {
+					if node.Content[i].Value == k {
+						next = node.Content[i+1]
+						break
+					}
+				}
+			}
+		}
+
+		if next == nil {
+			if depth == 0 {
+				return nil
+			}
+			break
+		}
+		node = next
+	}
+
+	return node
+}
+
+// isGitURL reports whether s is a Git URL or local repository path go-git is
+// able to clone from.
+func isGitURL(s string) bool {
+	if scpLikeURL.MatchString(s) || isLocalPath(s) {
+		return true
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+
+	switch u.Scheme {
+	case "http", "https", "ssh", "git":
+		return len(u.Host) > 0
+	case "file":
+		return len(u.Path) > 0
+	default:
+		return false
+	}
+}
+
+// isLocalPath reports whether s is a path of a local repository, i.e. an
+// existing path, an absolute path or a path relative to the working
+// directory starting with "./" or "../".
+func isLocalPath(s string) bool {
+	if _, err := os.Stat(s); err == nil {
+		return true
+	}
+
+	return filepath.IsAbs(s) ||
+		strings.HasPrefix(s, "./") ||
+		strings.HasPrefix(s, "../")
+}
+
+// isHTTPURL reports whether s is an absolute HTTP(S) URL, as required for
+// the API of hosting services.
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && len(u.Host) > 0
+}
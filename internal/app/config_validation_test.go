@@ -0,0 +1,375 @@
+package app_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flohansen/documenter/internal/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateConfig(t *testing.T) {
+	t.Run("should return no problems for valid config", func(t *testing.T) {
+		// assign
+		sshKey := filepath.Join(t.TempDir(), "id_ed25519")
+		if err := os.WriteFile(sshKey, []byte("key"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		b := []byte(strings.Join([]string{
+			"scraping:",
+			"  interval: 1m",
+			"docs:",
+			"  sections:",
+			"    - name: A",
+			"      type: git",
+			"      url: https://some.url.com/repo",
+			"    - name: B",
+			"      type: git",
+			"      url: git@some.url.com:repo.git",
+			"      sshKey: " + sshKey,
+		}, "\n"))
+
+		// act
+		problems := app.ValidateConfig(b)
+
+		// assert
+		assert.Empty(t, problems)
+	})
+
+	t.Run("should report all problems with line numbers", func(t *testing.T) {
+		// assign
+		b := []byte(strings.Join([]string{
+			"scraping:",
+			"  interval: 1s",
+			"docs:",
+			"  sections:",
+			"    - name: A",
+			"      type: git",
+			"      url: https://some.url.com/repo",
+			"    - name: A",
+			"      type: git",
+			"      url: not a url",
+			"      sshKey: /does/not/exist",
+			"    - type: git",
+		}, "\n"))
+
+		// act
+		problems := app.ValidateConfig(b)
+
+		// assert
+		var got []string
+		for _, problem := range problems {
+			got = append(got, problem.String())
+		}
+		assert.Equal(t, []string{
+			"line 2: scraping.interval: warning: interval is shorter than the recommended minimum of 10s",
+			"line 8: docs.sections[1].name: duplicate name \"A\", already used by docs.sections[0]",
+			"line 10: docs.sections[1].url: invalid git url \"not a url\"",
+			"line 11: docs.sections[1].sshKey: ssh key is not readable: open /does/not/exist: no such file or directory",
+			"line 12: docs.sections[2].name: name is required",
+			"line 12: docs.sections[2].url: url is required",
+		}, got)
+	})
+
+	t.Run("should report decoding problems together with validation problems", func(t *testing.T) {
+		// assign
+		b := []byte(strings.Join([]string{
+			"docs:",
+			"  sections:",
+			"    - name: A",
+			"      type: anything",
+			"      url: https://some.url.com/repo",
+			"logging:",
+			"  format: anything",
+		}, "\n"))
+
+		// act
+		problems := app.ValidateConfig(b)
+
+		// assert
+		var got []string
+		for _, problem := range problems {
+			got = append(got, problem.String())
+		}
+		assert.Equal(t, []string{
+			"line 4: unknown section type: anything",
+			"line 7: unknown logging format: anything",
+			"scraping.interval: interval is required",
+		}, got)
+	})
+
+	t.Run("should report syntax errors", func(t *testing.T) {
+		// assign
+		b := []byte("docs:\n  sections: [\n")
+
+		// act
+		problems := app.ValidateConfig(b)
+
+		// assert
+		if assert.Len(t, problems, 1) {
+			assert.Greater(t, problems[0].Line, 0)
+		}
+	})
+}
+
+func TestConfig_Validate(t *testing.T) {
+	t.Run("should return error for duplicate section names", func(t *testing.T) {
+		// assign
+		config := app.Config{
+			Scraping: app.ScrapingConfig{Interval: app.MinScrapingInterval},
+			Docs: app.DocsConfig{
+				Sections: []app.SectionConfig{
+					{Name: "A", URL: "https://some.url.com/a"},
+					{Name: "A", URL: "https://some.url.com/b"},
+				},
+			},
+		}
+
+		// act
+		err := config.Validate()
+
+		// assert
+		assert.EqualError(t, err, "docs.sections[1].name: duplicate name \"A\", already used by docs.sections[0]")
+	})
+
+	t.Run("should warn about short scraping interval", func(t *testing.T) {
+		// assign
+		config := app.Config{
+			Scraping: app.ScrapingConfig{Interval: time.Second},
+		}
+
+		// act
+		err := config.Validate()
+		warnings := config.Warnings()
+
+		// assert
+		assert.NoError(t, err)
+		if assert.Len(t, warnings, 1) {
+			assert.Equal(t, "scraping.interval: warning: interval is shorter than the recommended minimum of 10s", warnings[0].String())
+		}
+	})
+
+	t.Run("should accept local repository paths", func(t *testing.T) {
+		// assign
+		config := app.Config{
+			Scraping: app.ScrapingConfig{Interval: app.MinScrapingInterval},
+			Docs: app.DocsConfig{
+				Sections: []app.SectionConfig{
+					{Name: "A", URL: t.TempDir()},
+					{Name: "B", URL: "/srv/git/repo"},
+					{Name: "C", URL: "./repo"},
+					{Name: "D", URL: "../repo"},
+					{Name: "E", URL: "file:///srv/git/repo"},
+				},
+			},
+		}
+
+		// act
+		err := config.Validate()
+
+		// assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("should allow equal names in different namespaces", func(t *testing.T) {
+		// assign
+		config := app.Config{
+			Scraping: app.ScrapingConfig{Interval: app.MinScrapingInterval},
+			Docs: app.DocsConfig{
+				Sections: []app.SectionConfig{
+					{Name: "A", URL: "https://some.url.com/a"},
+					{Namespace: "team", Name: "A", URL: "https://some.url.com/b"},
+					{Namespace: "team", Name: "A", URL: "https://some.url.com/c"},
+					{Namespace: "team/a", Name: "B", URL: "https://some.url.com/d"},
+				},
+			},
+		}
+
+		// act
+		err := config.Validate()
+
+		// assert
+		assert.EqualError(t, err, strings.Join([]string{
+			"docs.sections[2].name: duplicate name \"team/A\", already used by docs.sections[1]",
+			"docs.sections[3].namespace: invalid namespace \"team/a\", must consist of letters, digits, '.', '_' and '-'",
+		}, "\n"))
+	})
+
+	t.Run("should return error for invalid database config", func(t *testing.T) {
+		// assign
+		config := app.Config{
+			Scraping: app.ScrapingConfig{Interval: app.MinScrapingInterval},
+			Database: app.DatabaseConfig{
+				MaxConns: 2,
+				MinConns: 4,
+				TLS: app.DatabaseTLSConfig{
+					CertFile: "/does/not/exist",
+				},
+			},
+		}
+
+		// act
+		err := config.Validate()
+
+		// assert
+		assert.EqualError(t, err, strings.Join([]string{
+			"database.minConns: minConns must not be greater than maxConns",
+			"database.tls: certFile and keyFile must be set together",
+			"database.tls.certFile: file is not readable: open /does/not/exist: no such file or directory",
+		}, "\n"))
+	})
+
+	t.Run("should return error for invalid object storage config", func(t *testing.T) {
+		// assign
+		config := app.Config{
+			Scraping: app.ScrapingConfig{Interval: app.MinScrapingInterval},
+			ObjectStorage: app.ObjectStorageConfig{
+				Endpoint:  "https://minio:9000",
+				AccessKey: "access",
+			},
+		}
+
+		// act
+		err := config.Validate()
+
+		// assert
+		assert.EqualError(t, err, strings.Join([]string{
+			"objectStorage.endpoint: endpoint must be in the form host:port",
+			"objectStorage.bucket: bucket is required when object storage is enabled",
+			"objectStorage: accessKey and secretKey must be set together",
+		}, "\n"))
+	})
+
+	t.Run("should return error for invalid metrics config", func(t *testing.T) {
+		// assign
+		config := app.Config{
+			Scraping: app.ScrapingConfig{Interval: app.MinScrapingInterval},
+			Metrics: app.MetricsConfig{
+				Address: "9090",
+				Path:    "metrics",
+			},
+		}
+
+		// act
+		err := config.Validate()
+
+		// assert
+		assert.EqualError(t, err, strings.Join([]string{
+			"metrics.address: address must be in the form host:port",
+			"metrics.path: path must start with /",
+		}, "\n"))
+	})
+
+	t.Run("should return error for invalid push config", func(t *testing.T) {
+		// assign
+		config := app.Config{
+			Scraping: app.ScrapingConfig{Interval: app.MinScrapingInterval},
+			Push: app.PushConfig{
+				Address: "8080",
+				Path:    "hooks/push",
+			},
+		}
+
+		// act
+		err := config.Validate()
+
+		// assert
+		assert.EqualError(t, err, strings.Join([]string{
+			"push.address: address must be in the form host:port",
+			"push.secret: secret is required when the push endpoint is enabled",
+			"push.path: path must start with /",
+		}, "\n"))
+	})
+
+	t.Run("should return error for invalid admin config", func(t *testing.T) {
+		// assign
+		config := app.Config{
+			Scraping: app.ScrapingConfig{Interval: app.MinScrapingInterval},
+			Admin:    app.AdminConfig{Address: "localhost"},
+		}
+
+		// act
+		err := config.Validate()
+
+		// assert
+		assert.EqualError(t, err, strings.Join([]string{
+			"admin.address: address must be in the form host:port",
+			"admin.token: token is required when the admin API is enabled",
+		}, "\n"))
+	})
+
+	t.Run("should return error for invalid webhooks", func(t *testing.T) {
+		// assign
+		config := app.Config{
+			Scraping: app.ScrapingConfig{Interval: app.MinScrapingInterval},
+			Webhooks: []app.WebhookConfig{
+				{URL: "https://hooks.example.com", Sections: []string{"team/*"}},
+				{Sections: []string{"team/["}},
+				{URL: "hooks.example.com"},
+			},
+		}
+
+		// act
+		err := config.Validate()
+
+		// assert
+		assert.EqualError(t, err, strings.Join([]string{
+			"webhooks[1].url: url is required",
+			"webhooks[1].sections[0]: invalid section pattern \"team/[\"",
+			"webhooks[2].url: invalid webhook url \"hooks.example.com\"",
+		}, "\n"))
+	})
+
+	t.Run("should return error for invalid section metadata", func(t *testing.T) {
+		// assign
+		config := app.Config{
+			Scraping: app.ScrapingConfig{Interval: app.MinScrapingInterval},
+			Docs: app.DocsConfig{
+				Sections: []app.SectionConfig{{
+					Name:     "A",
+					URL:      "https://some.url.com/a",
+					Tags:     []string{"go", " "},
+					Category: "platform//backend",
+				}},
+			},
+		}
+
+		// act
+		err := config.Validate()
+
+		// assert
+		assert.EqualError(t, err, strings.Join([]string{
+			"docs.sections[0].tags[1]: tag must not be empty",
+			"docs.sections[0].category: invalid category path \"platform//backend\"",
+		}, "\n"))
+	})
+
+	t.Run("should return error for invalid discovery section", func(t *testing.T) {
+		// assign
+		config := app.Config{
+			Scraping: app.ScrapingConfig{Interval: app.MinScrapingInterval},
+			Docs: app.DocsConfig{
+				Sections: []app.SectionConfig{{
+					Name:      "org",
+					Type:      app.SectionTypeDiscovery,
+					URL:       "git@github.com:org/repo.git",
+					Discovery: app.DiscoveryConfig{NamePattern: "("},
+				}},
+			},
+		}
+
+		// act
+		err := config.Validate()
+
+		// assert
+		assert.EqualError(t, err, strings.Join([]string{
+			"docs.sections[0].url: invalid api url \"git@github.com:org/repo.git\"",
+			"docs.sections[0].discovery.organization: organization is required",
+			"docs.sections[0].discovery.namePattern: invalid name pattern: error parsing regexp: missing closing ): `(`",
+		}, "\n"))
+	})
+}
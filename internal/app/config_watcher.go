@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// configWatchDebounce is the time to wait for further file events before the
+// configuration file is read again. Editors and orchestrators often replace
+// files in several steps.
+const configWatchDebounce = 100 * time.Millisecond
+
+// WatchConfig watches the configuration file with the given name and the
+// files it includes, and emits the parsed configuration whenever their
+// content changes on disk or the process receives SIGHUP. Configurations
+// which cannot be read or parsed are logged and skipped. The returned channel
+// is closed when the context is done.
+func WatchConfig(ctx context.Context, name string, logger Logger) (<-chan Config, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("could not create file watcher: %w", err)
+	}
+
+	// Watch the directories instead of the files themselves, so replacing a
+	// file (e.g. by atomic renames or Kubernetes ConfigMap updates) and adding
+	// new included files is detected.
+	if err := watcher.Add(filepath.Dir(name)); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("could not watch config directory: %w", err)
+	}
+
+	last, _ := readConfigFiles(name)
+	watchDirs(watcher, last.Dirs, logger)
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+
+	configs := make(chan Config)
+	go func() {
+		defer close(configs)
+		defer signal.Stop(sigChan)
+		defer watcher.Close()
+
+		debounce := time.NewTimer(0)
+		<-debounce.C
+
+		for {
+			force := false
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-watcher.Errors:
+				logger.Warn("config watcher error", "error", err)
+				continue
+			case <-watcher.Events:
+				debounce.Reset(configWatchDebounce)
+				continue
+			case <-debounce.C:
+			case <-sigChan:
+				force = true
+			}
+
+			set, err := readConfigFiles(name)
+			if err != nil {
+				logger.Warn("could not read config", "error", err)
+				continue
+			}
+			watchDirs(watcher, set.Dirs, logger)
+			if !force && set.Equal(last) {
+				continue
+			}
+			last = set
+
+			config, err := parseConfigFiles(set)
+			if err != nil {
+				logger.Warn("could not parse config", "error", err)
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case configs <- config:
+			}
+		}
+	}()
+
+	return configs, nil
+}
+
+// watchDirs adds the directories to the watcher. Directories which are already
+// watched are ignored by the watcher.
+func watchDirs(watcher *fsnotify.Watcher, dirs []string, logger Logger) {
+	for _, dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			logger.Warn("could not watch config directory", "dir", dir, "error", err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package app_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flohansen/documenter/internal/app"
+	"github.com/flohansen/documenter/internal/app/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWatchConfig(t *testing.T) {
+	t.Run("should emit config when file changes", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		loggerMock := mocks.NewMockLogger(ctrl)
+
+		name := filepath.Join(t.TempDir(), "documenter.config.yaml")
+		writeFile(t, name, "logging:\n  format: text\n")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		configs, err := app.WatchConfig(ctx, name, loggerMock)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		writeFile(t, name, "logging:\n  format: json\n")
+
+		// assert
+		select {
+		case config := <-configs:
+			assert.Equal(t, app.LoggingFormatJSON, config.Logging.Format)
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected config to be emitted")
+		}
+	})
+
+	t.Run("should skip invalid config", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		loggerMock := mocks.NewMockLogger(ctrl)
+
+		name := filepath.Join(t.TempDir(), "documenter.config.yaml")
+		writeFile(t, name, "logging:\n  format: text\n")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		configs, err := app.WatchConfig(ctx, name, loggerMock)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		warned := make(chan struct{})
+		loggerMock.EXPECT().
+			Warn("could not parse config", "error", gomock.Any()).
+			Do(func(string, ...any) { close(warned) }).
+			Times(1)
+
+		// act
+		writeFile(t, name, "logging:\n  format: anything wrong\n")
+
+		// assert
+		select {
+		case <-warned:
+		case config := <-configs:
+			t.Fatalf("unexpected config: %v", config)
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected invalid config to be logged")
+		}
+	})
+}
+
+func writeFile(t *testing.T, name, content string) {
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
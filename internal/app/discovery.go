@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/flohansen/documenter/internal/discovery"
+)
+
+// DiscoverSections lists the repositories of the organization configured in
+// the discovery section and returns a Git section for each repository passing
+// the section's filters. Generated sections are named "<section>/<repository>"
+// and use the section's namespace, SSH key, owner, tags and category. If an
+// SSH key is set, repositories are cloned via SSH, otherwise via HTTP(S).
+func DiscoverSections(ctx context.Context, section SectionConfig) ([]SectionConfig, error) {
+	pattern, err := regexp.Compile(section.Discovery.NamePattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid name pattern: %w", err)
+	}
+
+	filter := discovery.Filter{
+		Topic:           section.Discovery.Topic,
+		IncludeArchived: section.Discovery.IncludeArchived,
+	}
+	if len(section.Discovery.NamePattern) > 0 {
+		filter.NamePattern = pattern
+	}
+
+	repos, err := newDiscoveryClient(section).ListRepositories(ctx, section.Discovery.Organization)
+	if err != nil {
+		return nil, fmt.Errorf("list repositories error: %w", err)
+	}
+
+	var sections []SectionConfig
+	for _, repo := range repos {
+		if !filter.Match(repo) {
+			continue
+		}
+
+		url := repo.CloneURL
+		if len(section.SSHKey) > 0 {
+			url = repo.SSHURL
+		}
+
+		sections = append(sections, SectionConfig{
+			Namespace:   section.Namespace,
+			Name:        section.Name + "/" + repo.Name,
+			Type:        SectionTypeGit,
+			URL:         url,
+			SSHKey:      section.SSHKey,
+			Title:       repo.Name,
+			Description: repo.Description,
+			Owner:       section.Owner,
+			Tags:        section.Tags,
+			Category:    section.Category,
+		})
+	}
+
+	return sections, nil
+}
+
+func newDiscoveryClient(section SectionConfig) discovery.Client {
+	switch section.Discovery.Provider {
+	case DiscoveryProviderGitea:
+		return discovery.NewGiteaClient(section.URL, section.Discovery.Token)
+	case DiscoveryProviderGitLab:
+		return discovery.NewGitLabClient(section.URL, section.Discovery.Token)
+	default:
+		return discovery.NewGitHubClient(section.URL, section.Discovery.Token)
+	}
+}
@@ -0,0 +1,120 @@
+package app_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flohansen/documenter/internal/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoverSections(t *testing.T) {
+	repos := []map[string]any{
+		{"name": "service-docs", "description": "Service documentation", "clone_url": "https://example.com/org/service-docs.git", "ssh_url": "git@example.com:org/service-docs.git", "topics": []string{"docs"}},
+		{"name": "old-docs", "clone_url": "https://example.com/org/old-docs.git", "ssh_url": "git@example.com:org/old-docs.git", "topics": []string{"docs"}, "archived": true},
+		{"name": "website", "clone_url": "https://example.com/org/website.git", "ssh_url": "git@example.com:org/website.git", "topics": []string{"docs"}},
+		{"name": "lib-docs", "clone_url": "https://example.com/org/lib-docs.git", "ssh_url": "git@example.com:org/lib-docs.git"},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/orgs/org/repos" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		json.NewEncoder(w).Encode(repos)
+	}))
+	defer server.Close()
+
+	t.Run("should generate git sections for matching repositories", func(t *testing.T) {
+		// assign
+		section := app.SectionConfig{
+			Name:     "org",
+			Type:     app.SectionTypeDiscovery,
+			URL:      server.URL,
+			Owner:    "team-a",
+			Tags:     []string{"service"},
+			Category: "platform",
+			Discovery: app.DiscoveryConfig{
+				Provider:     app.DiscoveryProviderGitea,
+				Organization: "org",
+				Topic:        "docs",
+				NamePattern:  `-docs$`,
+			},
+		}
+
+		// act
+		sections, err := app.DiscoverSections(context.Background(), section)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []app.SectionConfig{
+			{
+				Name:        "org/service-docs",
+				Type:        app.SectionTypeGit,
+				URL:         "https://example.com/org/service-docs.git",
+				Title:       "service-docs",
+				Description: "Service documentation",
+				Owner:       "team-a",
+				Tags:        []string{"service"},
+				Category:    "platform",
+			},
+		}, sections)
+	})
+
+	t.Run("should clone via ssh if ssh key is set", func(t *testing.T) {
+		// assign
+		section := app.SectionConfig{
+			Name:   "org",
+			Type:   app.SectionTypeDiscovery,
+			URL:    server.URL,
+			SSHKey: "/path/to/key",
+			Discovery: app.DiscoveryConfig{
+				Provider:        app.DiscoveryProviderGitea,
+				Organization:    "org",
+				IncludeArchived: true,
+			},
+		}
+
+		// act
+		sections, err := app.DiscoverSections(context.Background(), section)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"org/service-docs", "org/old-docs", "org/website", "org/lib-docs"}, names(sections))
+		assert.Equal(t, "git@example.com:org/old-docs.git", sections[1].URL)
+		assert.Equal(t, "/path/to/key", sections[1].SSHKey)
+	})
+
+	t.Run("should return error if repositories cannot be listed", func(t *testing.T) {
+		// assign
+		section := app.SectionConfig{
+			Name: "org",
+			Type: app.SectionTypeDiscovery,
+			URL:  server.URL,
+			Discovery: app.DiscoveryConfig{
+				Provider:     app.DiscoveryProviderGitea,
+				Organization: "unknown",
+			},
+		}
+
+		// act
+		sections, err := app.DiscoverSections(context.Background(), section)
+
+		// assert
+		assert.ErrorContains(t, err, "list repositories error")
+		assert.Nil(t, sections)
+	})
+}
+
+func names(sections []app.SectionConfig) []string {
+	var names []string
+	for _, section := range sections {
+		names = append(names, section.Name)
+	}
+
+	return names
+}
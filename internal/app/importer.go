@@ -2,16 +2,29 @@ package app
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/flohansen/documenter/internal/domain"
 	"github.com/flohansen/documenter/internal/scraper"
+	"github.com/flohansen/documenter/internal/webhook"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
+const tracerName = "github.com/flohansen/documenter/internal/app"
+
 //go:generate mockgen -destination=mocks/scraper.go -package=mocks . Scraper
 
 // Scraper defines the interface for documentation scrapers.
@@ -41,7 +54,49 @@ type Logger interface {
 // DocumentationRepository defines the interface for persisting documentation data.
 // It provides methods for writing data to a database.
 type DocumentationRepository interface {
-	UpsertDocumentation(ctx context.Context, doc domain.Documentation) error
+	// UpsertDocumentation inserts or updates the documentation and reports
+	// whether it was created or its content changed.
+	UpsertDocumentation(ctx context.Context, doc domain.Documentation) (bool, error)
+	// UpsertSectionDocumentations writes all documentations of the section
+	// at once and deletes the section's documentations which are not part of
+	// docs. It reports whether any documentation was created, changed or
+	// deleted.
+	UpsertSectionDocumentations(ctx context.Context, section domain.DocumentationKey, docs []domain.Documentation) (bool, error)
+	// DeleteDocumentationsExcept deletes all documentations whose section key
+	// is not in keys and returns the number of deleted documentations.
+	DeleteDocumentationsExcept(ctx context.Context, keys []domain.DocumentationKey) (int64, error)
+	// ArchiveDocumentationsExcept moves all documentations whose section key
+	// is not in keys to the archive and returns the number of archived
+	// documentations.
+	ArchiveDocumentationsExcept(ctx context.Context, keys []domain.DocumentationKey) (int64, error)
+	// MarkDocumentationsStaleExcept marks all documentations whose section key
+	// is not in keys as stale and returns the number of newly marked
+	// documentations.
+	MarkDocumentationsStaleExcept(ctx context.Context, keys []domain.DocumentationKey) (int64, error)
+}
+
+//go:generate mockgen -destination=mocks/section_locker.go -package=mocks . SectionLocker
+
+// SectionLocker defines the interface for claiming sections across multiple
+// importer replicas, so each section is scraped by exactly one replica at a time.
+type SectionLocker interface {
+	// TryLock tries to claim the section with the given name for the owner.
+	// It returns true if the section is claimed by this replica. Scrapers of
+	// the same section on this replica may hold the claim at the same time,
+	// e.g. while a scraper is restarted.
+	TryLock(ctx context.Context, name, owner string) (bool, error)
+	// Unlock releases the claim of the owner on the section with the given
+	// name. The section is released once no owner holds it anymore.
+	Unlock(ctx context.Context, name, owner string) error
+}
+
+//go:generate mockgen -destination=mocks/webhook_sender.go -package=mocks . WebhookSender
+
+// WebhookSender defines the interface for delivering webhooks about changed
+// documentations, see webhook.Dispatcher.
+type WebhookSender interface {
+	// Send queues the delivery of the event to the hook.
+	Send(hook webhook.Hook, event webhook.Event)
 }
 
 // Importer represents the main command-line interface application.
@@ -51,6 +106,28 @@ type Importer struct {
 	Scrapers   []Scraper               // List of active scrapers
 	Logger     Logger                  // Logger instance for application logging
 	Repository DocumentationRepository // Repository to persist documentation data
+	Tracer     trace.Tracer            // Tracer used to record spans (tracing is disabled if nil)
+	Reloads    <-chan Config           // Updated configurations to reconcile while running (optional)
+	Locker     SectionLocker           // Locker to claim sections across replicas (optional)
+	Webhooks   WebhookSender           // Sender of the configured webhooks (optional)
+
+	// ScraperFactory creates scrapers for sections added by a reload. It
+	// defaults to NewScraper using Tracer if nil.
+	ScraperFactory func(section SectionConfig) (Scraper, bool)
+	// Discoverer generates the Git sections of a discovery section. It
+	// defaults to DiscoverSections if nil.
+	Discoverer func(ctx context.Context, section SectionConfig) ([]SectionConfig, error)
+
+	active          []SectionConfig            // Sections backing the running scrapers
+	scraperKeys     []domain.DocumentationKey  // Keys of the sections of Scrapers while reconciling
+	discovered      map[string][]SectionConfig // Last generated sections by discovery section
+	discoveryFailed bool                       // Whether the last discovery of any section failed
+
+	webhooks   atomic.Pointer[[]WebhookConfig] // Webhooks of the current configuration
+	lockOwners atomic.Uint64                   // Last lock owner token handed to a scraper
+
+	mu       sync.Mutex                                  // Guards triggers
+	triggers map[domain.DocumentationKey]*sectionTrigger // Triggers of the running scrapers by section
 }
 
 // NewImporter creates a new CLI instance with the provided configuration.
@@ -58,13 +135,12 @@ type Importer struct {
 // the appropriate logger format. Scrapers are created for each configured
 // documentation section, and unsupported section types are skipped.
 func NewImporter(repo DocumentationRepository, cfg Config) *Importer {
+	tracer := otel.Tracer(tracerName)
+
 	var scrapers []Scraper
 	for _, section := range cfg.Docs.Sections {
-		var s Scraper
-		switch section.Type {
-		case SectionTypeGit:
-			s = scraper.NewGitScraper(section.Name, section.URL, scraper.WithSSHKey(section.SSHKey))
-		default:
+		s, ok := NewScraper(section, tracer)
+		if !ok {
 			continue
 		}
 
@@ -85,65 +161,507 @@ func NewImporter(repo DocumentationRepository, cfg Config) *Importer {
 		Scrapers:   scrapers,
 		Logger:     logger,
 		Repository: repo,
+		Tracer:     tracer,
+	}
+}
+
+// NewScraper creates the scraper matching the type of the given section,
+// recording its spans using the tracer (the global tracer if nil). It returns
+// false if the section type is not supported.
+func NewScraper(section SectionConfig, tracer trace.Tracer) (Scraper, bool) {
+	switch section.Type {
+	case SectionTypeGit:
+		return scraper.NewGitScraper(section.Name, section.URL,
+			scraper.WithSSHKey(section.SSHKey),
+			scraper.WithTracer(tracer)), true
+	default:
+		return nil, false
 	}
 }
 
 // Run starts the CLI application and begins the scraping process.
 // It launches each configured scraper in its own goroutine and waits for
 // all scrapers to complete. The method blocks until the context is cancelled
-// or all scrapers have finished execution.
+// or all scrapers have finished execution. If Reloads is set, every received
+// configuration is reconciled against the running scrapers until the context
+// is cancelled. Discovery sections are expanded into Git sections after
+// each scraping interval in the same way.
 func (i *Importer) Run(ctx context.Context) error {
+	i.setWebhooks(i.Config.Webhooks)
+	if i.Reloads != nil || hasDiscoverySections(i.Config.Docs.Sections) {
+		return i.runWithReloads(ctx)
+	}
+
+	i.active = i.Config.Docs.Sections
+	sections := pairSections(i.Scrapers, i.active)
 	var wg sync.WaitGroup
 
-	for _, s := range i.Scrapers {
+	for n, s := range i.Scrapers {
 		wg.Add(1)
 
 		go func() {
 			defer wg.Done()
-			i.startScraper(ctx, s)
+			i.startScraper(ctx, s, sections[n], i.Config.Scraping.Interval)
 		}()
 	}
 
+	if i.Config.Docs.Prune.Mode != PruneModeNone {
+		i.startPruner(ctx)
+	}
+
 	wg.Wait()
 	return nil
 }
 
+// runWithReloads runs the scrapers like Run, but reconciles them with every
+// configuration received from Reloads and with the sections discovered after
+// each scraping interval until the context is cancelled.
+func (i *Importer) runWithReloads(ctx context.Context) error {
+	var wg sync.WaitGroup
+	running := make(map[domain.DocumentationKey]context.CancelFunc)
+
+	i.active = staticSections(i.Config.Docs.Sections)
+	i.scraperKeys = nil
+	for n, section := range pairSections(i.Scrapers, i.active) {
+		i.runScraper(ctx, &wg, running, i.Scrapers[n], section, i.Config.Scraping.Interval)
+		i.scraperKeys = append(i.scraperKeys, section.Key())
+	}
+	i.discover(ctx, &wg, running)
+
+	// A single ticker is used, so frequent reloads do not postpone discovery
+	// and pruning.
+	ticker := time.NewTicker(i.Config.Scraping.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		case cfg, ok := <-i.Reloads:
+			if !ok {
+				wg.Wait()
+				return nil
+			}
+
+			i.reload(ctx, &wg, running, cfg, ticker)
+		case <-ticker.C:
+			i.discover(ctx, &wg, running)
+			if err := i.prune(ctx); err != nil {
+				i.Logger.Warn("prune error", "error", err)
+			}
+		}
+	}
+}
+
+// runScraper starts the scraper of the section in its own goroutine and
+// registers a cancel function under the section's key, so it can be stopped
+// individually.
+func (i *Importer) runScraper(ctx context.Context, wg *sync.WaitGroup, running map[domain.DocumentationKey]context.CancelFunc, s Scraper, section SectionConfig, interval time.Duration) {
+	ctx, cancel := context.WithCancel(ctx)
+	key := section.Key()
+	if prev, ok := running[key]; ok {
+		running[key] = func() { prev(); cancel() }
+	} else {
+		running[key] = cancel
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		i.startScraper(ctx, s, section, interval)
+	}()
+}
+
+// reload reconciles the running scrapers with the given configuration. New
+// sections are started, removed sections are stopped and changed sections are
+// restarted. If the scraping interval changed, the ticker of discovery and
+// pruning is reset to it. Invalid configurations are rejected and leave the
+// running scrapers untouched.
+func (i *Importer) reload(ctx context.Context, wg *sync.WaitGroup, running map[domain.DocumentationKey]context.CancelFunc, cfg Config, ticker *time.Ticker) {
+	if err := cfg.Validate(); err != nil {
+		i.Logger.Warn("rejected config reload", "error", err)
+		return
+	}
+	for _, warning := range cfg.Warnings() {
+		i.Logger.Warn("config warning", "problem", warning.String())
+	}
+
+	restart := cfg.Scraping.Interval != i.Config.Scraping.Interval
+	if restart {
+		ticker.Reset(cfg.Scraping.Interval)
+	}
+	i.Config = cfg
+	i.setWebhooks(cfg.Webhooks)
+	diff := i.reconcile(ctx, wg, running, i.desiredSections(ctx), restart)
+
+	i.Logger.Info("reloaded config",
+		"added", sectionNames(diff.Added),
+		"removed", sectionNames(diff.Removed),
+		"changed", sectionNames(diff.Changed))
+
+	if len(diff.Removed) > 0 {
+		if err := i.prune(ctx); err != nil {
+			i.Logger.Warn("prune error", "error", err)
+		}
+	}
+}
+
+// discover reconciles the running scrapers with the sections generated by
+// the configured discovery sections.
+func (i *Importer) discover(ctx context.Context, wg *sync.WaitGroup, running map[domain.DocumentationKey]context.CancelFunc) {
+	if !hasDiscoverySections(i.Config.Docs.Sections) {
+		return
+	}
+
+	diff := i.reconcile(ctx, wg, running, i.desiredSections(ctx), false)
+	if len(diff.Added) > 0 || len(diff.Removed) > 0 || len(diff.Changed) > 0 {
+		i.Logger.Info("discovered sections",
+			"added", sectionNames(diff.Added),
+			"removed", sectionNames(diff.Removed),
+			"changed", sectionNames(diff.Changed))
+	}
+}
+
+// desiredSections returns the sections of the current configuration with
+// every discovery section replaced by the sections it generates. If the
+// discovery of a section fails, its previously generated sections are kept.
+// Generated sections never replace sections with the same name.
+func (i *Importer) desiredSections(ctx context.Context) []SectionConfig {
+	sections := staticSections(i.Config.Docs.Sections)
+	keys := make(map[domain.DocumentationKey]bool)
+	for _, section := range sections {
+		keys[section.Key()] = true
+	}
+
+	discovered := make(map[string][]SectionConfig)
+	i.discoveryFailed = false
+	for _, section := range i.Config.Docs.Sections {
+		if section.Type != SectionTypeDiscovery {
+			continue
+		}
+
+		generated, err := i.discoverSections(ctx, section)
+		if err != nil {
+			i.Logger.Warn("discovery error", "section", section.Name, "error", err)
+			generated = i.discovered[section.Name]
+			i.discoveryFailed = true
+		}
+		discovered[section.Name] = generated
+
+		for _, g := range generated {
+			if keys[g.Key()] {
+				i.Logger.Warn("skipped discovered section with duplicate name", "name", g.Key().String())
+				continue
+			}
+
+			keys[g.Key()] = true
+			sections = append(sections, g)
+		}
+	}
+	i.discovered = discovered
+
+	return sections
+}
+
+// discoverSections generates the sections of the discovery section using the
+// configured discoverer within its own span.
+func (i *Importer) discoverSections(ctx context.Context, section SectionConfig) (sections []SectionConfig, err error) {
+	ctx, span := i.startSpan(ctx, "discover", attribute.String("section.name", section.Name))
+	defer func() { endSpan(span, err) }()
+
+	if i.Discoverer != nil {
+		return i.Discoverer(ctx, section)
+	}
+
+	return DiscoverSections(ctx, section)
+}
+
+// reconcile starts, stops and restarts the running scrapers, so they match
+// the desired sections. If restartAll is set, unchanged sections are
+// restarted as well (e.g. because the scraping interval changed).
+func (i *Importer) reconcile(ctx context.Context, wg *sync.WaitGroup, running map[domain.DocumentationKey]context.CancelFunc, desired []SectionConfig, restartAll bool) sectionDiff {
+	diff := diffSections(i.active, desired)
+	if restartAll {
+		diff.Changed = append(diff.Changed, diff.Unchanged...)
+		diff.Unchanged = nil
+	}
+
+	for _, section := range append(diff.Removed, diff.Changed...) {
+		if cancel, ok := running[section.Key()]; ok {
+			cancel()
+			delete(running, section.Key())
+		}
+	}
+
+	scrapers := make([]Scraper, 0, len(i.Scrapers))
+	keys := make([]domain.DocumentationKey, 0, len(i.Scrapers))
+	for n, s := range i.Scrapers {
+		if _, ok := running[i.scraperKeys[n]]; ok {
+			scrapers = append(scrapers, s)
+			keys = append(keys, i.scraperKeys[n])
+		}
+	}
+
+	for _, section := range append(diff.Added, diff.Changed...) {
+		s, ok := i.newScraper(section)
+		if !ok {
+			continue
+		}
+
+		i.runScraper(ctx, wg, running, s, section, i.Config.Scraping.Interval)
+		scrapers = append(scrapers, s)
+		keys = append(keys, section.Key())
+	}
+
+	i.active = desired
+	i.Scrapers = scrapers
+	i.scraperKeys = keys
+
+	return diff
+}
+
+// pairSections returns the section of each scraper. Scrapers are paired with
+// the first unused section of the same name, as NewImporter creates them in
+// the order of the sections. Scrapers without a section are paired with an
+// empty section of their name.
+func pairSections(scrapers []Scraper, sections []SectionConfig) []SectionConfig {
+	used := make([]bool, len(sections))
+	paired := make([]SectionConfig, 0, len(scrapers))
+	for _, s := range scrapers {
+		section := SectionConfig{Name: s.Name()}
+		for n := range sections {
+			if !used[n] && sections[n].Name == s.Name() {
+				used[n] = true
+				section = sections[n]
+				break
+			}
+		}
+
+		paired = append(paired, section)
+	}
+
+	return paired
+}
+
+// newScraper creates a scraper for the section using the configured factory.
+func (i *Importer) newScraper(section SectionConfig) (Scraper, bool) {
+	if i.ScraperFactory != nil {
+		return i.ScraperFactory(section)
+	}
+
+	return NewScraper(section, i.Tracer)
+}
+
 // startScraper runs a single scraper in a continuous loop.
-// It periodically executes the scraper based on the configured interval
+// It periodically executes the scraper based on the given interval
 // and handles scraping errors by logging warnings. The method respects
-// context cancellation and will exit when the context is done.
-func (i *Importer) startScraper(ctx context.Context, scraper Scraper) {
-	if err := i.scraperLoop(ctx, scraper); err != nil {
+// context cancellation and will exit when the context is done. The namespace
+// and metadata of the section are persisted with every scraped documentation.
+// Triggered scrapes run immediately and restart the interval.
+func (i *Importer) startScraper(ctx context.Context, scraper Scraper, section SectionConfig, interval time.Duration) {
+	trigger := i.registerTrigger(section)
+	defer i.unregisterTrigger(trigger)
+
+	owner := strconv.FormatUint(i.lockOwners.Add(1), 10)
+	if i.Locker != nil {
+		defer func() {
+			if err := i.Locker.Unlock(context.WithoutCancel(ctx), section.Key().String(), owner); err != nil {
+				i.Logger.Warn("section unlock error", "error", err)
+			}
+		}()
+	}
+
+	scrape := func() error {
+		err := i.claimAndScrape(ctx, scraper, section, owner)
+		i.setClaimed(trigger, !errors.Is(err, ErrSectionClaimed))
+		return err
+	}
+
+	scrape()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+			scrape()
+		case <-trigger.ch:
+			waiters := i.takeWaiters(trigger)
+			err := scrape()
+			for _, w := range waiters {
+				w <- err
+			}
+		}
+	}
+}
+
+// claimAndScrape runs a single scraping step if the section can be claimed by
+// this replica for the owner. Without a locker, every section is considered claimed. Errors
+// are logged and returned, so the outcome can be reported to waiters of
+// triggered scrapes.
+func (i *Importer) claimAndScrape(ctx context.Context, scraper Scraper, section SectionConfig, owner string) error {
+	if i.Locker != nil {
+		ok, err := i.Locker.TryLock(ctx, section.Key().String(), owner)
+		if err != nil {
+			i.Logger.Warn("section lock error", "error", err)
+			return fmt.Errorf("section lock error: %w", err)
+		}
+		if !ok {
+			return ErrSectionClaimed
+		}
+	}
+
+	if err := i.scraperLoop(ctx, scraper, section); err != nil {
 		i.Logger.Warn("scraper error", "error", err)
+		return err
 	}
 
+	return nil
+}
+
+// startPruner periodically prunes documentations without a configured section
+// after each scraping interval. The method blocks until the context is done.
+func (i *Importer) startPruner(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-time.After(i.Config.Scraping.Interval):
-			if err := i.scraperLoop(ctx, scraper); err != nil {
-				i.Logger.Warn("scraper error", "error", err)
+			if err := i.prune(ctx); err != nil {
+				i.Logger.Warn("prune error", "error", err)
 			}
 		}
 	}
 }
 
+// prune handles documentations which are no longer backed by any configured
+// or discovered section according to the configured prune mode. Pruning is
+// skipped while the discovery of any section fails, so the documentations of
+// its repositories are not pruned because of an unavailable API. Deleting is
+// also skipped without any active section, as it would delete every
+// documentation.
+func (i *Importer) prune(ctx context.Context) (err error) {
+	if i.Config.Docs.Prune.Mode == PruneModeNone {
+		return nil
+	}
+	if i.discoveryFailed {
+		i.Logger.Warn("skipped prune because discovery failed")
+		return nil
+	}
+
+	ctx, span := i.startSpan(ctx, "prune")
+	defer func() { endSpan(span, err) }()
+
+	keys := documentationKeys(i.active)
+	if i.Config.Docs.Prune.Mode == PruneModeDelete && len(keys) == 0 {
+		i.Logger.Warn("skipped prune because no section is active")
+		return nil
+	}
+
+	var n int64
+	switch i.Config.Docs.Prune.Mode {
+	case PruneModeDelete:
+		n, err = i.Repository.DeleteDocumentationsExcept(ctx, keys)
+	case PruneModeArchive:
+		n, err = i.Repository.ArchiveDocumentationsExcept(ctx, keys)
+	case PruneModeStale:
+		n, err = i.Repository.MarkDocumentationsStaleExcept(ctx, keys)
+	}
+	if err != nil {
+		return fmt.Errorf("prune documentations error: %w", err)
+	}
+
+	if n > 0 {
+		i.Logger.Info("pruned documentations", "count", n)
+	}
+	return nil
+}
+
 // scraperLoop represents a single step in the scraping loop. It tries to
 // scrape its target and persist the data.
-func (i *Importer) scraperLoop(ctx context.Context, scraper Scraper) error {
+func (i *Importer) scraperLoop(ctx context.Context, scraper Scraper, section SectionConfig) (err error) {
+	ctx, span := i.startSpan(ctx, "scraperLoop", attribute.String("section.name", scraper.Name()))
+	defer func() { endSpan(span, err) }()
+
 	md, err := scraper.Scrape(ctx)
 	if err != nil {
 		return fmt.Errorf("scrape error: %w", err)
 	}
 
-	if err := i.Repository.UpsertDocumentation(ctx, domain.Documentation{
-		Name:    scraper.Name(),
-		Content: md,
-	}); err != nil {
+	changed, err := i.upsertDocumentation(ctx, section.Key(), domain.Documentation{
+		Namespace: section.Namespace,
+		Name:      scraper.Name(),
+		Content:   md,
+		Metadata:  section.Metadata(),
+	})
+	if err != nil {
 		return fmt.Errorf("upsert documentation error: %w", err)
 	}
 
+	if changed {
+		i.notifyWebhooks(domain.DocumentationKey{Namespace: section.Key().Namespace, Name: scraper.Name()}, md)
+	}
+
 	i.Logger.Info("scraped target", "name", scraper.Name())
 	return nil
 }
+
+// upsertDocumentation persists the documentation as the only documentation
+// of the section within its own span.
+func (i *Importer) upsertDocumentation(ctx context.Context, section domain.DocumentationKey, doc domain.Documentation) (changed bool, err error) {
+	ctx, span := i.startSpan(ctx, "UpsertSectionDocumentations", attribute.Int("documentation.size", len(doc.Content)))
+	defer func() { endSpan(span, err) }()
+
+	return i.Repository.UpsertSectionDocumentations(ctx, section, []domain.Documentation{doc})
+}
+
+// setWebhooks replaces the webhooks notified about changed documentations.
+// Scrapers read them concurrently to reloads of the configuration.
+func (i *Importer) setWebhooks(hooks []WebhookConfig) {
+	i.webhooks.Store(&hooks)
+}
+
+// notifyWebhooks sends an event about the changed documentation to every
+// configured webhook matching its key.
+func (i *Importer) notifyWebhooks(key domain.DocumentationKey, content []byte) {
+	hooks := i.webhooks.Load()
+	if i.Webhooks == nil || hooks == nil {
+		return
+	}
+
+	sum := sha256.Sum256(content)
+	event := webhook.Event{
+		Type:      webhook.EventDocumentationChanged,
+		Namespace: key.Namespace,
+		Name:      key.Name,
+		Hash:      hex.EncodeToString(sum[:]),
+		Time:      time.Now().UTC(),
+	}
+
+	for _, hook := range *hooks {
+		if hook.Matches(key) {
+			i.Webhooks.Send(webhook.Hook{URL: hook.URL, Secret: hook.Secret}, event)
+		}
+	}
+}
+
+// startSpan starts a new span using the importer's tracer. If no tracer is
+// configured, the context is returned unchanged together with a no-op span.
+func (i *Importer) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if i.Tracer == nil {
+		return ctx, noop.Span{}
+	}
+
+	return i.Tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err on the span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -2,15 +2,23 @@ package app_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/flohansen/documenter/internal/app"
 	"github.com/flohansen/documenter/internal/app/mocks"
 	"github.com/flohansen/documenter/internal/domain"
+	"github.com/flohansen/documenter/internal/webhook"
 	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/mock/gomock"
 )
 
@@ -68,11 +76,11 @@ func TestCli_Run(t *testing.T) {
 			Times(2)
 
 		repoMock.EXPECT().
-			UpsertDocumentation(ctx, domain.Documentation{
+			UpsertSectionDocumentations(ctx, domain.DocumentationKey{Namespace: domain.DefaultNamespace, Name: "name"}, []domain.Documentation{{
 				Name:    "name",
 				Content: []byte{},
-			}).
-			Return(nil).
+			}}).
+			Return(true, nil).
 			Times(2)
 
 		scraperMock.EXPECT().
@@ -92,6 +100,62 @@ func TestCli_Run(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("should persist section metadata", func(t *testing.T) {
+		// assign
+		ctx, cancel := context.WithCancel(context.Background())
+		cli := app.Importer{
+			Config: app.Config{
+				Scraping: app.ScrapingConfig{
+					Interval: time.Hour,
+				},
+				Docs: app.DocsConfig{
+					Sections: []app.SectionConfig{{
+						Name:        "name",
+						Title:       "Name",
+						Description: "Description",
+						Owner:       "team-a",
+						Tags:        []string{"go"},
+						Category:    "platform/backend",
+					}},
+				},
+			},
+			Scrapers:   []app.Scraper{scraperMock},
+			Logger:     loggerMock,
+			Repository: repoMock,
+		}
+
+		loggerMock.EXPECT().
+			Info("scraped target", "name", "name").
+			Times(1)
+
+		repoMock.EXPECT().
+			UpsertSectionDocumentations(ctx, domain.DocumentationKey{Namespace: domain.DefaultNamespace, Name: "name"}, []domain.Documentation{{
+				Name:    "name",
+				Content: []byte{},
+				Metadata: domain.Metadata{
+					Title:       "Name",
+					Description: "Description",
+					Owner:       "team-a",
+					Tags:        []string{"go"},
+					Category:    "platform/backend",
+				},
+			}}).
+			Return(true, nil).
+			Times(1)
+
+		scraperMock.EXPECT().
+			Scrape(ctx).
+			Do(func(_ context.Context) { cancel() }).
+			Return([]byte{}, nil).
+			Times(1)
+
+		// act
+		err := cli.Run(ctx)
+
+		// assert
+		assert.NoError(t, err)
+	})
+
 	t.Run("should log warning if scraping fails but continue", func(t *testing.T) {
 		// assign
 		ctx, cancel := context.WithCancel(context.Background())
@@ -114,11 +178,11 @@ func TestCli_Run(t *testing.T) {
 			Times(1)
 
 		repoMock.EXPECT().
-			UpsertDocumentation(ctx, domain.Documentation{
+			UpsertSectionDocumentations(ctx, domain.DocumentationKey{Namespace: domain.DefaultNamespace, Name: "name"}, []domain.Documentation{{
 				Name:    "name",
 				Content: []byte{},
-			}).
-			Return(nil).
+			}}).
+			Return(true, nil).
 			Times(1)
 
 		scraperMock.EXPECT().
@@ -137,4 +201,998 @@ func TestCli_Run(t *testing.T) {
 		// assert
 		assert.NoError(t, err)
 	})
+
+	t.Run("should record spans for scraper loop and upsert", func(t *testing.T) {
+		// assign
+		ctx, cancel := context.WithCancel(context.Background())
+		recorder := tracetest.NewSpanRecorder()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		cli := app.Importer{
+			Config: app.Config{
+				Scraping: app.ScrapingConfig{
+					Interval: 10 * time.Millisecond,
+				},
+			},
+			Scrapers:   []app.Scraper{scraperMock},
+			Logger:     loggerMock,
+			Repository: repoMock,
+			Tracer:     tp.Tracer("test"),
+		}
+
+		loggerMock.EXPECT().
+			Info("scraped target", "name", "name").
+			Times(1)
+
+		repoMock.EXPECT().
+			UpsertSectionDocumentations(gomock.Any(), domain.DocumentationKey{Namespace: domain.DefaultNamespace, Name: "name"}, []domain.Documentation{{
+				Name:    "name",
+				Content: []byte{},
+			}}).
+			Return(true, nil).
+			Times(1)
+
+		scraperMock.EXPECT().
+			Scrape(gomock.Any()).
+			Do(func(_ context.Context) { cancel() }).
+			Return([]byte{}, nil).
+			Times(1)
+
+		// act
+		err := cli.Run(ctx)
+
+		// assert
+		assert.NoError(t, err)
+
+		spans := recorder.Ended()
+		if assert.Len(t, spans, 2) {
+			assert.Equal(t, "UpsertSectionDocumentations", spans[0].Name())
+			assert.Equal(t, "scraperLoop", spans[1].Name())
+			assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+		}
+	})
+}
+
+func TestCli_Reload(t *testing.T) {
+	t.Run("should start added and stop removed sections", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		scraperA := mocks.NewMockScraper(ctrl)
+		scraperB := mocks.NewMockScraper(ctrl)
+		loggerMock := mocks.NewMockLogger(ctrl)
+		repoMock := mocks.NewMockDocumentationRepository(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		reloads := make(chan app.Config)
+		cli := app.Importer{
+			Config: app.Config{
+				Scraping: app.ScrapingConfig{Interval: time.Hour},
+				Docs: app.DocsConfig{
+					Sections: []app.SectionConfig{{Name: "a"}},
+				},
+			},
+			Scrapers:   []app.Scraper{scraperA},
+			Logger:     loggerMock,
+			Repository: repoMock,
+			Reloads:    reloads,
+			ScraperFactory: func(section app.SectionConfig) (app.Scraper, bool) {
+				assert.Equal(t, "b", section.Name)
+				return scraperB, true
+			},
+		}
+
+		scraperA.EXPECT().Name().Return("a").AnyTimes()
+		scraperB.EXPECT().Name().Return("b").AnyTimes()
+		scraperA.EXPECT().Scrape(gomock.Any()).Return([]byte{}, nil).AnyTimes()
+		scraperB.EXPECT().
+			Scrape(gomock.Any()).
+			Do(func(_ context.Context) { cancel() }).
+			Return([]byte{}, nil).
+			Times(1)
+
+		repoMock.EXPECT().UpsertSectionDocumentations(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
+		loggerMock.EXPECT().Info("scraped target", "name", gomock.Any()).AnyTimes()
+		loggerMock.EXPECT().
+			Info("reloaded config", "added", []string{"b"}, "removed", []string{"a"}, "changed", []string{}).
+			Times(1)
+
+		// act
+		go func() {
+			reloads <- app.Config{
+				Scraping: app.ScrapingConfig{Interval: time.Hour},
+				Docs: app.DocsConfig{
+					Sections: []app.SectionConfig{{Name: "b", URL: "https://some.url.com/b"}},
+				},
+			}
+		}()
+		err := cli.Run(ctx)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []app.Scraper{scraperB}, cli.Scrapers)
+		assert.Equal(t, []app.SectionConfig{{Name: "b", URL: "https://some.url.com/b"}}, cli.Config.Docs.Sections)
+	})
+
+	t.Run("should prune after scraping interval despite frequent reloads", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		loggerMock := mocks.NewMockLogger(ctrl)
+		repoMock := mocks.NewMockDocumentationRepository(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		reloads := make(chan app.Config)
+		config := app.Config{
+			Scraping: app.ScrapingConfig{Interval: 50 * time.Millisecond},
+			Docs: app.DocsConfig{
+				Prune: app.PruneConfig{Mode: app.PruneModeStale},
+			},
+		}
+		cli := app.Importer{
+			Config:     config,
+			Logger:     loggerMock,
+			Repository: repoMock,
+			Reloads:    reloads,
+		}
+
+		loggerMock.EXPECT().Warn("config warning", "problem", gomock.Any()).AnyTimes()
+		loggerMock.EXPECT().Info("reloaded config", gomock.Any()).AnyTimes()
+		repoMock.EXPECT().
+			MarkDocumentationsStaleExcept(gomock.Any(), gomock.Any()).
+			Do(func(context.Context, []domain.DocumentationKey) { cancel() }).
+			Return(int64(0), nil).
+			MinTimes(1)
+
+		// act
+		go func() {
+			ticker := time.NewTicker(5 * time.Millisecond)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					select {
+					case reloads <- config:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}()
+		err := cli.Run(ctx)
+
+		// assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("should reject invalid config and keep running sections", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		scraperMock := mocks.NewMockScraper(ctrl)
+		loggerMock := mocks.NewMockLogger(ctrl)
+		repoMock := mocks.NewMockDocumentationRepository(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		reloads := make(chan app.Config)
+		config := app.Config{
+			Scraping: app.ScrapingConfig{Interval: time.Hour},
+			Docs: app.DocsConfig{
+				Sections: []app.SectionConfig{{Name: "a"}},
+			},
+		}
+		cli := app.Importer{
+			Config:     config,
+			Scrapers:   []app.Scraper{scraperMock},
+			Logger:     loggerMock,
+			Repository: repoMock,
+			Reloads:    reloads,
+			ScraperFactory: func(section app.SectionConfig) (app.Scraper, bool) {
+				t.Fatal("scraper factory must not be called")
+				return nil, false
+			},
+		}
+
+		scraperMock.EXPECT().Name().Return("a").AnyTimes()
+		scraperMock.EXPECT().Scrape(gomock.Any()).Return([]byte{}, nil).AnyTimes()
+		repoMock.EXPECT().UpsertSectionDocumentations(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
+		loggerMock.EXPECT().Info("scraped target", "name", "a").AnyTimes()
+		loggerMock.EXPECT().
+			Warn("rejected config reload", "error", gomock.Any()).
+			Do(func(string, ...any) { cancel() }).
+			Times(1)
+
+		// act
+		go func() {
+			reloads <- app.Config{
+				Docs: app.DocsConfig{
+					Sections: []app.SectionConfig{{Name: "a"}, {Name: "a"}},
+				},
+			}
+		}()
+		err := cli.Run(ctx)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []app.Scraper{scraperMock}, cli.Scrapers)
+		assert.Equal(t, config, cli.Config)
+	})
+}
+
+func TestCli_Prune(t *testing.T) {
+	pruneKeys := []domain.DocumentationKey{
+		{Namespace: domain.DefaultNamespace, Name: "a"},
+		{Namespace: "team", Name: "a"},
+	}
+
+	tests := []struct {
+		name   string
+		mode   app.PruneMode
+		expect func(repo *mocks.MockDocumentationRepository) *gomock.Call
+	}{
+		{
+			name: "should delete documentations without section",
+			mode: app.PruneModeDelete,
+			expect: func(repo *mocks.MockDocumentationRepository) *gomock.Call {
+				return repo.EXPECT().DeleteDocumentationsExcept(gomock.Any(), pruneKeys)
+			},
+		},
+		{
+			name: "should archive documentations without section",
+			mode: app.PruneModeArchive,
+			expect: func(repo *mocks.MockDocumentationRepository) *gomock.Call {
+				return repo.EXPECT().ArchiveDocumentationsExcept(gomock.Any(), pruneKeys)
+			},
+		},
+		{
+			name: "should mark documentations without section as stale",
+			mode: app.PruneModeStale,
+			expect: func(repo *mocks.MockDocumentationRepository) *gomock.Call {
+				return repo.EXPECT().MarkDocumentationsStaleExcept(gomock.Any(), pruneKeys)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// assign
+			ctrl := gomock.NewController(t)
+			loggerMock := mocks.NewMockLogger(ctrl)
+			repoMock := mocks.NewMockDocumentationRepository(ctrl)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cli := app.Importer{
+				Config: app.Config{
+					Scraping: app.ScrapingConfig{Interval: 10 * time.Millisecond},
+					Docs: app.DocsConfig{
+						Sections: []app.SectionConfig{{Name: "a"}, {Namespace: "team", Name: "a"}},
+						Prune:    app.PruneConfig{Mode: tt.mode},
+					},
+				},
+				Logger:     loggerMock,
+				Repository: repoMock,
+			}
+
+			tt.expect(repoMock).
+				Do(func(context.Context, []domain.DocumentationKey) { cancel() }).
+				Return(int64(1), nil).
+				Times(1)
+			loggerMock.EXPECT().
+				Info("pruned documentations", "count", int64(1)).
+				Times(1)
+
+			// act
+			err := cli.Run(ctx)
+
+			// assert
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("should log warning if pruning fails", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		loggerMock := mocks.NewMockLogger(ctrl)
+		repoMock := mocks.NewMockDocumentationRepository(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cli := app.Importer{
+			Config: app.Config{
+				Scraping: app.ScrapingConfig{Interval: 10 * time.Millisecond},
+				Docs: app.DocsConfig{
+					Prune: app.PruneConfig{Mode: app.PruneModeArchive},
+				},
+			},
+			Logger:     loggerMock,
+			Repository: repoMock,
+		}
+
+		repoMock.EXPECT().
+			ArchiveDocumentationsExcept(gomock.Any(), []domain.DocumentationKey{}).
+			Do(func(context.Context, []domain.DocumentationKey) { cancel() }).
+			Return(int64(0), errors.New("error")).
+			Times(1)
+		loggerMock.EXPECT().
+			Warn("prune error", "error", fmt.Errorf("prune documentations error: %w", errors.New("error"))).
+			Times(1)
+
+		// act
+		err := cli.Run(ctx)
+
+		// assert
+		assert.NoError(t, err)
+	})
+}
+
+func TestCli_Locker(t *testing.T) {
+	t.Run("should scrape claimed section and release it when done", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		scraperMock := mocks.NewMockScraper(ctrl)
+		loggerMock := mocks.NewMockLogger(ctrl)
+		repoMock := mocks.NewMockDocumentationRepository(ctrl)
+		lockerMock := mocks.NewMockSectionLocker(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cli := app.Importer{
+			Config: app.Config{
+				Scraping: app.ScrapingConfig{Interval: 10 * time.Millisecond},
+			},
+			Scrapers:   []app.Scraper{scraperMock},
+			Logger:     loggerMock,
+			Repository: repoMock,
+			Locker:     lockerMock,
+		}
+
+		scraperMock.EXPECT().Name().Return("name").AnyTimes()
+		lockerMock.EXPECT().TryLock(ctx, "name", gomock.Any()).Return(true, nil).Times(1)
+		scraperMock.EXPECT().
+			Scrape(ctx).
+			Do(func(_ context.Context) { cancel() }).
+			Return([]byte{}, nil).
+			Times(1)
+		repoMock.EXPECT().UpsertSectionDocumentations(ctx, gomock.Any(), gomock.Any()).Return(true, nil).Times(1)
+		loggerMock.EXPECT().Info("scraped target", "name", "name").Times(1)
+		lockerMock.EXPECT().Unlock(gomock.Any(), "name", gomock.Any()).Return(nil).Times(1)
+
+		// act
+		err := cli.Run(ctx)
+
+		// assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("should skip section claimed by another replica", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		scraperMock := mocks.NewMockScraper(ctrl)
+		loggerMock := mocks.NewMockLogger(ctrl)
+		repoMock := mocks.NewMockDocumentationRepository(ctrl)
+		lockerMock := mocks.NewMockSectionLocker(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cli := app.Importer{
+			Config: app.Config{
+				Scraping: app.ScrapingConfig{Interval: 10 * time.Millisecond},
+			},
+			Scrapers:   []app.Scraper{scraperMock},
+			Logger:     loggerMock,
+			Repository: repoMock,
+			Locker:     lockerMock,
+		}
+
+		scraperMock.EXPECT().Name().Return("name").AnyTimes()
+		lockerMock.EXPECT().TryLock(ctx, "name", gomock.Any()).Return(false, nil).Times(1)
+		lockerMock.EXPECT().
+			TryLock(ctx, "name", gomock.Any()).
+			Do(func(context.Context, string, string) { cancel() }).
+			Return(false, nil).
+			Times(1)
+		lockerMock.EXPECT().Unlock(gomock.Any(), "name", gomock.Any()).Return(nil).Times(1)
+
+		// act
+		err := cli.Run(ctx)
+
+		// assert
+		assert.NoError(t, err)
+	})
+}
+
+func TestCli_Discovery(t *testing.T) {
+	t.Run("should scrape discovered sections", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		scraperMock := mocks.NewMockScraper(ctrl)
+		loggerMock := mocks.NewMockLogger(ctrl)
+		repoMock := mocks.NewMockDocumentationRepository(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		discoverySection := app.SectionConfig{Name: "org", Type: app.SectionTypeDiscovery}
+		generated := app.SectionConfig{Name: "org/repo", Type: app.SectionTypeGit, URL: "https://some.url.com/repo"}
+		cli := app.Importer{
+			Config: app.Config{
+				Scraping: app.ScrapingConfig{Interval: time.Hour},
+				Docs: app.DocsConfig{
+					Sections: []app.SectionConfig{discoverySection},
+				},
+			},
+			Logger:     loggerMock,
+			Repository: repoMock,
+			Discoverer: func(_ context.Context, section app.SectionConfig) ([]app.SectionConfig, error) {
+				assert.Equal(t, discoverySection, section)
+				return []app.SectionConfig{generated}, nil
+			},
+			ScraperFactory: func(section app.SectionConfig) (app.Scraper, bool) {
+				assert.Equal(t, generated, section)
+				return scraperMock, true
+			},
+		}
+
+		scraperMock.EXPECT().Name().Return("org/repo").AnyTimes()
+		scraperMock.EXPECT().
+			Scrape(gomock.Any()).
+			Do(func(_ context.Context) { cancel() }).
+			Return([]byte("content"), nil).
+			Times(1)
+		repoMock.EXPECT().
+			UpsertSectionDocumentations(gomock.Any(), domain.DocumentationKey{Namespace: domain.DefaultNamespace, Name: "org/repo"}, []domain.Documentation{{Name: "org/repo", Content: []byte("content")}}).
+			Return(true, nil).
+			Times(1)
+		loggerMock.EXPECT().Info("scraped target", "name", "org/repo").AnyTimes()
+		loggerMock.EXPECT().
+			Info("discovered sections", "added", []string{"default/org/repo"}, "removed", []string{}, "changed", []string{}).
+			Times(1)
+
+		// act
+		err := cli.Run(ctx)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []app.Scraper{scraperMock}, cli.Scrapers)
+	})
+
+	t.Run("should skip deleting documentations without any active section", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		loggerMock := mocks.NewMockLogger(ctrl)
+		repoMock := mocks.NewMockDocumentationRepository(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cli := app.Importer{
+			Config: app.Config{
+				Scraping: app.ScrapingConfig{Interval: time.Millisecond},
+				Docs: app.DocsConfig{
+					Prune: app.PruneConfig{Mode: app.PruneModeDelete},
+				},
+			},
+			Logger:     loggerMock,
+			Repository: repoMock,
+		}
+
+		loggerMock.EXPECT().
+			Warn("skipped prune because no section is active").
+			Do(func(string, ...any) { cancel() }).
+			MinTimes(1)
+
+		// act
+		err := cli.Run(ctx)
+
+		// assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("should skip prune if discovery fails", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		loggerMock := mocks.NewMockLogger(ctrl)
+		repoMock := mocks.NewMockDocumentationRepository(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cli := app.Importer{
+			Config: app.Config{
+				Scraping: app.ScrapingConfig{Interval: time.Millisecond},
+				Docs: app.DocsConfig{
+					Sections: []app.SectionConfig{{Name: "org", Type: app.SectionTypeDiscovery}},
+					Prune:    app.PruneConfig{Mode: app.PruneModeDelete},
+				},
+			},
+			Logger:     loggerMock,
+			Repository: repoMock,
+			Discoverer: func(context.Context, app.SectionConfig) ([]app.SectionConfig, error) {
+				return nil, errors.New("unavailable")
+			},
+		}
+
+		loggerMock.EXPECT().Warn("discovery error", "section", "org", "error", gomock.Any()).MinTimes(1)
+		loggerMock.EXPECT().
+			Warn("skipped prune because discovery failed").
+			Do(func(string, ...any) { cancel() }).
+			MinTimes(1)
+
+		// act
+		err := cli.Run(ctx)
+
+		// assert
+		assert.NoError(t, err)
+	})
+}
+
+func TestCli_Namespaces(t *testing.T) {
+	t.Run("should persist sections with equal names in their namespaces", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		scraperA := mocks.NewMockScraper(ctrl)
+		scraperB := mocks.NewMockScraper(ctrl)
+		loggerMock := mocks.NewMockLogger(ctrl)
+		repoMock := mocks.NewMockDocumentationRepository(ctrl)
+		lockerMock := mocks.NewMockSectionLocker(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cli := app.Importer{
+			Config: app.Config{
+				Scraping: app.ScrapingConfig{Interval: time.Hour},
+				Docs: app.DocsConfig{
+					Sections: []app.SectionConfig{
+						{Namespace: "a", Name: "docs"},
+						{Namespace: "b", Name: "docs"},
+					},
+				},
+			},
+			Scrapers:   []app.Scraper{scraperA, scraperB},
+			Logger:     loggerMock,
+			Repository: repoMock,
+			Locker:     lockerMock,
+		}
+
+		var upserts sync.WaitGroup
+		upserts.Add(2)
+		go func() {
+			upserts.Wait()
+			cancel()
+		}()
+
+		scraperA.EXPECT().Name().Return("docs").AnyTimes()
+		scraperB.EXPECT().Name().Return("docs").AnyTimes()
+		scraperA.EXPECT().Scrape(gomock.Any()).Return([]byte("a"), nil).Times(1)
+		scraperB.EXPECT().Scrape(gomock.Any()).Return([]byte("b"), nil).Times(1)
+		lockerMock.EXPECT().TryLock(gomock.Any(), "a/docs", gomock.Any()).Return(true, nil).Times(1)
+		lockerMock.EXPECT().TryLock(gomock.Any(), "b/docs", gomock.Any()).Return(true, nil).Times(1)
+		lockerMock.EXPECT().Unlock(gomock.Any(), "a/docs", gomock.Any()).Return(nil).Times(1)
+		lockerMock.EXPECT().Unlock(gomock.Any(), "b/docs", gomock.Any()).Return(nil).Times(1)
+		repoMock.EXPECT().
+			UpsertSectionDocumentations(gomock.Any(), domain.DocumentationKey{Namespace: "a", Name: "docs"}, []domain.Documentation{{Namespace: "a", Name: "docs", Content: []byte("a")}}).
+			Do(func(context.Context, domain.DocumentationKey, []domain.Documentation) { upserts.Done() }).
+			Return(true, nil).
+			Times(1)
+		repoMock.EXPECT().
+			UpsertSectionDocumentations(gomock.Any(), domain.DocumentationKey{Namespace: "b", Name: "docs"}, []domain.Documentation{{Namespace: "b", Name: "docs", Content: []byte("b")}}).
+			Do(func(context.Context, domain.DocumentationKey, []domain.Documentation) { upserts.Done() }).
+			Return(true, nil).
+			Times(1)
+		loggerMock.EXPECT().Info("scraped target", "name", "docs").Times(2)
+
+		// act
+		err := cli.Run(ctx)
+
+		// assert
+		assert.NoError(t, err)
+	})
+}
+
+func TestCli_Webhooks(t *testing.T) {
+	t.Run("should send event to matching webhooks after changed upsert", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		scraperMock := mocks.NewMockScraper(ctrl)
+		loggerMock := mocks.NewMockLogger(ctrl)
+		repoMock := mocks.NewMockDocumentationRepository(ctrl)
+		senderMock := mocks.NewMockWebhookSender(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cli := app.Importer{
+			Config: app.Config{
+				Scraping: app.ScrapingConfig{Interval: time.Hour},
+				Docs: app.DocsConfig{
+					Sections: []app.SectionConfig{{Namespace: "team", Name: "docs"}},
+				},
+				Webhooks: []app.WebhookConfig{
+					{URL: "https://a.example.com", Secret: "secret"},
+					{URL: "https://b.example.com", Sections: []string{"team/*"}},
+					{URL: "https://c.example.com", Sections: []string{"other/*"}},
+				},
+			},
+			Scrapers:   []app.Scraper{scraperMock},
+			Logger:     loggerMock,
+			Repository: repoMock,
+			Webhooks:   senderMock,
+		}
+
+		scraperMock.EXPECT().Name().Return("docs").AnyTimes()
+		scraperMock.EXPECT().
+			Scrape(ctx).
+			Do(func(_ context.Context) { cancel() }).
+			Return([]byte("content"), nil).
+			Times(1)
+		repoMock.EXPECT().UpsertSectionDocumentations(ctx, gomock.Any(), gomock.Any()).Return(true, nil).Times(1)
+		loggerMock.EXPECT().Info("scraped target", "name", "docs").Times(1)
+
+		var hooks []webhook.Hook
+		var events []webhook.Event
+		senderMock.EXPECT().
+			Send(gomock.Any(), gomock.Any()).
+			Do(func(hook webhook.Hook, event webhook.Event) {
+				hooks = append(hooks, hook)
+				events = append(events, event)
+			}).
+			Times(2)
+
+		// act
+		err := cli.Run(ctx)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []webhook.Hook{
+			{URL: "https://a.example.com", Secret: "secret"},
+			{URL: "https://b.example.com"},
+		}, hooks)
+		if assert.Len(t, events, 2) {
+			assert.Equal(t, webhook.EventDocumentationChanged, events[0].Type)
+			assert.Equal(t, "team", events[0].Namespace)
+			assert.Equal(t, "docs", events[0].Name)
+			assert.Equal(t, "ed7002b439e9ac845f22357d822bac1444730fbdb6016d3ec9432297b9ec9f73", events[0].Hash)
+		}
+	})
+
+	t.Run("should not send events after unchanged upsert", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		scraperMock := mocks.NewMockScraper(ctrl)
+		loggerMock := mocks.NewMockLogger(ctrl)
+		repoMock := mocks.NewMockDocumentationRepository(ctrl)
+		senderMock := mocks.NewMockWebhookSender(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cli := app.Importer{
+			Config: app.Config{
+				Scraping: app.ScrapingConfig{Interval: time.Hour},
+				Webhooks: []app.WebhookConfig{{URL: "https://a.example.com"}},
+			},
+			Scrapers:   []app.Scraper{scraperMock},
+			Logger:     loggerMock,
+			Repository: repoMock,
+			Webhooks:   senderMock,
+		}
+
+		scraperMock.EXPECT().Name().Return("docs").AnyTimes()
+		scraperMock.EXPECT().
+			Scrape(ctx).
+			Do(func(_ context.Context) { cancel() }).
+			Return([]byte("content"), nil).
+			Times(1)
+		repoMock.EXPECT().UpsertSectionDocumentations(ctx, gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+		loggerMock.EXPECT().Info("scraped target", "name", "docs").Times(1)
+		senderMock.EXPECT().Send(gomock.Any(), gomock.Any()).Times(0)
+
+		// act
+		err := cli.Run(ctx)
+
+		// assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("should deliver signed event to local receiver", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		scraperMock := mocks.NewMockScraper(ctrl)
+		loggerMock := mocks.NewMockLogger(ctrl)
+		repoMock := mocks.NewMockDocumentationRepository(ctrl)
+
+		type request struct {
+			signature string
+			body      []byte
+		}
+		requests := make(chan request, 1)
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			requests <- request{signature: r.Header.Get(webhook.SignatureHeader), body: body}
+		}))
+		defer receiver.Close()
+
+		dispatcherCtx, stop := context.WithCancel(context.Background())
+		defer stop()
+		dispatcher := webhook.NewDispatcher()
+		go dispatcher.Run(dispatcherCtx)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cli := app.Importer{
+			Config: app.Config{
+				Scraping: app.ScrapingConfig{Interval: time.Hour},
+				Webhooks: []app.WebhookConfig{{URL: receiver.URL, Secret: "secret"}},
+			},
+			Scrapers:   []app.Scraper{scraperMock},
+			Logger:     loggerMock,
+			Repository: repoMock,
+			Webhooks:   dispatcher,
+		}
+
+		scraperMock.EXPECT().Name().Return("docs").AnyTimes()
+		scraperMock.EXPECT().
+			Scrape(ctx).
+			Do(func(_ context.Context) { cancel() }).
+			Return([]byte("content"), nil).
+			Times(1)
+		repoMock.EXPECT().UpsertSectionDocumentations(ctx, gomock.Any(), gomock.Any()).Return(true, nil).Times(1)
+		loggerMock.EXPECT().Info("scraped target", "name", "docs").Times(1)
+
+		// act
+		err := cli.Run(ctx)
+
+		// assert
+		assert.NoError(t, err)
+		select {
+		case req := <-requests:
+			var event webhook.Event
+			assert.NoError(t, json.Unmarshal(req.body, &event))
+			assert.Equal(t, domain.DefaultNamespace, event.Namespace)
+			assert.Equal(t, "docs", event.Name)
+			assert.Equal(t, webhook.Sign("secret", req.body), req.signature)
+		case <-time.After(5 * time.Second):
+			t.Fatal("receiver was not called")
+		}
+	})
+}
+
+func TestCli_TriggerRepository(t *testing.T) {
+	t.Run("should scrape sections of repository once after burst of triggers", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		scraperMock := mocks.NewMockScraper(ctrl)
+		loggerMock := mocks.NewMockLogger(ctrl)
+		repoMock := mocks.NewMockDocumentationRepository(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cli := app.Importer{
+			Config: app.Config{
+				Scraping: app.ScrapingConfig{Interval: time.Hour},
+				Docs: app.DocsConfig{
+					Sections: []app.SectionConfig{{Name: "name", URL: "https://github.com/org/Repo.git"}},
+				},
+			},
+			Scrapers:   []app.Scraper{scraperMock},
+			Logger:     loggerMock,
+			Repository: repoMock,
+		}
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		scraperMock.EXPECT().Name().Return("name").AnyTimes()
+		scraperMock.EXPECT().
+			Scrape(ctx).
+			Do(func(_ context.Context) {
+				close(started)
+				<-release
+			}).
+			Return([]byte{}, nil).
+			Times(1)
+		scraperMock.EXPECT().
+			Scrape(ctx).
+			Do(func(_ context.Context) { cancel() }).
+			Return([]byte{}, nil).
+			Times(1)
+		repoMock.EXPECT().UpsertSectionDocumentations(ctx, gomock.Any(), gomock.Any()).Return(false, nil).Times(2)
+		loggerMock.EXPECT().Info("scraped target", "name", "name").Times(2)
+		loggerMock.EXPECT().Info("triggered sections", "sections", []string{"name"}).Times(3)
+
+		done := make(chan error)
+		go func() { done <- cli.Run(ctx) }()
+		<-started
+
+		// act
+		var triggered [][]string
+		for _, url := range []string{"git@github.com:org/repo.git", "https://github.com/org/repo", "ssh://git@github.com/org/repo.git"} {
+			names, _ := cli.TriggerRepository([]string{url})
+			triggered = append(triggered, names)
+		}
+		close(release)
+
+		// assert
+		assert.NoError(t, <-done)
+		assert.Equal(t, [][]string{{"name"}, {"name"}, {"name"}}, triggered)
+	})
+
+	t.Run("should not trigger sections of other repositories", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		scraperMock := mocks.NewMockScraper(ctrl)
+		loggerMock := mocks.NewMockLogger(ctrl)
+		repoMock := mocks.NewMockDocumentationRepository(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		cli := app.Importer{
+			Config: app.Config{
+				Scraping: app.ScrapingConfig{Interval: time.Hour},
+				Docs: app.DocsConfig{
+					Sections: []app.SectionConfig{{Name: "name", URL: "https://github.com/org/repo.git"}},
+				},
+			},
+			Scrapers:   []app.Scraper{scraperMock},
+			Logger:     loggerMock,
+			Repository: repoMock,
+		}
+
+		started := make(chan struct{})
+		scraperMock.EXPECT().Name().Return("name").AnyTimes()
+		scraperMock.EXPECT().
+			Scrape(ctx).
+			Do(func(_ context.Context) { close(started) }).
+			Return([]byte{}, nil).
+			Times(1)
+		repoMock.EXPECT().UpsertSectionDocumentations(ctx, gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+		loggerMock.EXPECT().Info("scraped target", "name", "name").Times(1)
+
+		done := make(chan error)
+		go func() { done <- cli.Run(ctx) }()
+		<-started
+
+		// act
+		triggered, claimed := cli.TriggerRepository([]string{"https://github.com/org/other.git", "https://gitlab.com/org/repo.git"})
+
+		// assert
+		assert.Empty(t, triggered)
+		assert.Empty(t, claimed)
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	t.Run("should report sections claimed by another replica without triggering them", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		scraperMock := mocks.NewMockScraper(ctrl)
+		loggerMock := mocks.NewMockLogger(ctrl)
+		repoMock := mocks.NewMockDocumentationRepository(ctrl)
+		lockerMock := mocks.NewMockSectionLocker(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		cli := app.Importer{
+			Config: app.Config{
+				Scraping: app.ScrapingConfig{Interval: time.Hour},
+				Docs: app.DocsConfig{
+					Sections: []app.SectionConfig{{Name: "name", URL: "https://github.com/org/repo.git"}},
+				},
+			},
+			Scrapers:   []app.Scraper{scraperMock},
+			Logger:     loggerMock,
+			Repository: repoMock,
+			Locker:     lockerMock,
+		}
+
+		started := make(chan struct{})
+		scraperMock.EXPECT().Name().Return("name").AnyTimes()
+		lockerMock.EXPECT().
+			TryLock(ctx, "name", gomock.Any()).
+			Do(func(context.Context, string, string) { close(started) }).
+			Return(false, nil).
+			Times(1)
+		lockerMock.EXPECT().Unlock(gomock.Any(), "name", gomock.Any()).Return(nil).Times(1)
+
+		done := make(chan error)
+		go func() { done <- cli.Run(ctx) }()
+		<-started
+
+		// act
+		triggered, claimed := cli.TriggerRepository([]string{"git@github.com:org/repo.git"})
+
+		// assert
+		assert.Empty(t, triggered)
+		assert.Equal(t, []string{"name"}, claimed)
+		cancel()
+		assert.NoError(t, <-done)
+	})
+}
+
+func TestCli_TriggerSection(t *testing.T) {
+	t.Run("should scrape section and report outcome", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		scraperMock := mocks.NewMockScraper(ctrl)
+		loggerMock := mocks.NewMockLogger(ctrl)
+		repoMock := mocks.NewMockDocumentationRepository(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		cli := app.Importer{
+			Config: app.Config{
+				Scraping: app.ScrapingConfig{Interval: time.Hour},
+				Docs: app.DocsConfig{
+					Sections: []app.SectionConfig{{Namespace: "team", Name: "name"}},
+				},
+			},
+			Scrapers:   []app.Scraper{scraperMock},
+			Logger:     loggerMock,
+			Repository: repoMock,
+		}
+
+		started := make(chan struct{})
+		scraperMock.EXPECT().Name().Return("name").AnyTimes()
+		scraperMock.EXPECT().
+			Scrape(ctx).
+			Do(func(_ context.Context) { close(started) }).
+			Return([]byte{}, nil).
+			Times(1)
+		scraperMock.EXPECT().
+			Scrape(ctx).
+			Return(nil, errors.New("clone error")).
+			Times(1)
+		repoMock.EXPECT().UpsertSectionDocumentations(ctx, gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+		loggerMock.EXPECT().Info("scraped target", "name", "name").Times(1)
+		loggerMock.EXPECT().Info("triggered sections", "sections", []string{"team/name"}).Times(1)
+		loggerMock.EXPECT().Warn("scraper error", "error", gomock.Any()).Times(1)
+
+		done := make(chan error)
+		go func() { done <- cli.Run(ctx) }()
+		<-started
+
+		// act
+		err := cli.TriggerSection(context.Background(), "team/name")
+
+		// assert
+		assert.EqualError(t, err, "scrape error: clone error")
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	t.Run("should report section claimed by another replica", func(t *testing.T) {
+		// assign
+		ctrl := gomock.NewController(t)
+		scraperMock := mocks.NewMockScraper(ctrl)
+		loggerMock := mocks.NewMockLogger(ctrl)
+		repoMock := mocks.NewMockDocumentationRepository(ctrl)
+		lockerMock := mocks.NewMockSectionLocker(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		cli := app.Importer{
+			Config: app.Config{
+				Scraping: app.ScrapingConfig{Interval: time.Hour},
+			},
+			Scrapers:   []app.Scraper{scraperMock},
+			Logger:     loggerMock,
+			Repository: repoMock,
+			Locker:     lockerMock,
+		}
+
+		started := make(chan struct{})
+		scraperMock.EXPECT().Name().Return("name").AnyTimes()
+		lockerMock.EXPECT().
+			TryLock(ctx, "name", gomock.Any()).
+			Do(func(context.Context, string, string) { close(started) }).
+			Return(false, nil).
+			Times(1)
+		lockerMock.EXPECT().TryLock(ctx, "name", gomock.Any()).Return(false, nil).Times(1)
+		lockerMock.EXPECT().Unlock(gomock.Any(), "name", gomock.Any()).Return(nil).Times(1)
+		loggerMock.EXPECT().Info("triggered sections", "sections", []string{"name"}).Times(1)
+
+		done := make(chan error)
+		go func() { done <- cli.Run(ctx) }()
+		<-started
+
+		// act
+		err := cli.TriggerSection(context.Background(), "name")
+
+		// assert
+		assert.ErrorIs(t, err, app.ErrSectionClaimed)
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	t.Run("should return error for section which is not running", func(t *testing.T) {
+		// assign
+		cli := app.Importer{}
+
+		// act
+		err := cli.TriggerSection(context.Background(), "name")
+
+		// assert
+		assert.ErrorIs(t, err, app.ErrSectionNotRunning)
+	})
 }
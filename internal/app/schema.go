@@ -0,0 +1,27 @@
+package app
+
+import (
+	"embed"
+)
+
+//go:generate go test -run TestConfigSchema -update .
+
+// schemas contains the JSON schemas of the configuration files. They are
+// generated from the configuration types and kept in sync by
+// TestConfigSchema.
+//
+//go:embed schema/*.schema.json
+var schemas embed.FS
+
+// ConfigSchema returns the JSON schema of the configuration file.
+func ConfigSchema() []byte {
+	b, _ := schemas.ReadFile("schema/config.schema.json")
+	return b
+}
+
+// SectionsFileSchema returns the JSON schema of files included by the
+// configuration file.
+func SectionsFileSchema() []byte {
+	b, _ := schemas.ReadFile("schema/sections.schema.json")
+	return b
+}
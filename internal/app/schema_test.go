@@ -0,0 +1,252 @@
+package app_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flohansen/documenter/internal/app"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+var update = flag.Bool("update", false, "update the generated JSON schemas")
+
+func TestConfigSchema(t *testing.T) {
+	comments := parseFieldComments(t, ".")
+
+	tests := []struct {
+		name   string
+		file   string
+		title  string
+		root   reflect.Type
+		actual []byte
+	}{
+		{
+			name:   "config",
+			file:   "schema/config.schema.json",
+			title:  "Documenter configuration",
+			root:   reflect.TypeFor[app.Config](),
+			actual: app.ConfigSchema(),
+		},
+		{
+			name:   "sections file",
+			file:   "schema/sections.schema.json",
+			title:  "Documenter sections file",
+			root:   reflect.TypeFor[app.SectionsFile](),
+			actual: app.SectionsFileSchema(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("should be in sync with "+tt.name+" types", func(t *testing.T) {
+			// assign
+			g := schemaGenerator{comments: comments}
+			schema := g.schema(tt.root)
+			schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+			schema["title"] = tt.title
+
+			// act
+			b, err := json.MarshalIndent(schema, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			b = append(b, '\n')
+
+			// assert
+			if *update {
+				if err := os.WriteFile(tt.file, b, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			assert.Equal(t, string(b), string(tt.actual), "schema is out of date, run: go generate ./internal/app")
+		})
+	}
+}
+
+func TestConfigSchema_EnumValues(t *testing.T) {
+	enums := []interface {
+		yaml.Unmarshaler
+		EnumValues() []string
+	}{
+		new(app.SectionType),
+		new(app.PruneMode),
+		new(app.LoggingFormat),
+		new(app.DatabaseTLSMode),
+		new(app.DiscoveryProvider),
+	}
+
+	for _, enum := range enums {
+		t.Run(fmt.Sprintf("should unmarshal all values of %T", enum), func(t *testing.T) {
+			for _, value := range enum.EnumValues() {
+				// act
+				err := enum.UnmarshalYAML(&yaml.Node{Kind: yaml.ScalarNode, Value: value})
+
+				// assert
+				assert.NoError(t, err, value)
+			}
+		})
+	}
+}
+
+// envPattern matches values containing environment variables, which are
+// accepted for every field and expanded before decoding.
+const envPattern = `\$\{[A-Za-z_][A-Za-z0-9_]*(:-[^}]*)?\}`
+
+// durationPattern matches durations accepted by time.ParseDuration.
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// schemaGenerator generates JSON schemas from the configuration types using
+// their yaml tags, enum values and field comments.
+type schemaGenerator struct {
+	comments map[string]map[string]string
+}
+
+func (g schemaGenerator) schema(t reflect.Type) map[string]any {
+	if enum, ok := reflect.New(t).Interface().(interface{ EnumValues() []string }); ok {
+		return withEnv(map[string]any{"type": "string", "enum": enum.EnumValues()})
+	}
+
+	if t == reflect.TypeFor[time.Duration]() {
+		return withEnv(map[string]any{"type": "string", "pattern": durationPattern})
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return g.object(t)
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return withEnv(map[string]any{"type": "boolean"})
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return withEnv(map[string]any{"type": "integer"})
+	default:
+		panic(fmt.Sprintf("unsupported type %s", t))
+	}
+}
+
+func (g schemaGenerator) object(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+
+		property := g.schema(field.Type)
+		if description, ok := g.comments[t.Name()][field.Name]; ok {
+			property["description"] = description
+		}
+		if env := field.Tag.Get("env"); len(env) > 0 {
+			property["description"] = fmt.Sprintf("%s (overridden by %s)", property["description"], env)
+		}
+		properties[name] = property
+
+		if field.Type.Kind() == reflect.String {
+			properties[name+"_file"] = map[string]any{
+				"type":        "string",
+				"description": fmt.Sprintf("Path to a file containing the value of %s", name),
+			}
+		}
+	}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if description, ok := g.comments[t.Name()][""]; ok {
+		schema["description"] = description
+	}
+
+	return schema
+}
+
+// withEnv allows the schema's values to be given as environment variables.
+func withEnv(schema map[string]any) map[string]any {
+	return map[string]any{
+		"anyOf": []any{
+			schema,
+			map[string]any{"type": "string", "pattern": envPattern},
+		},
+	}
+}
+
+// parseFieldComments returns the comments of all struct types and their
+// fields in the package located in dir. The comment of the type itself is
+// stored for the empty field name.
+func parseFieldComments(t *testing.T, dir string) map[string]map[string]string {
+	fset := token.NewFileSet()
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	comments := make(map[string]map[string]string)
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				structType, ok := typeSpec.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+
+				fields := make(map[string]string)
+				if gen.Doc != nil {
+					fields[""] = joinLines(gen.Doc.Text())
+				}
+				for _, field := range structType.Fields.List {
+					comment := field.Comment
+					if comment == nil {
+						comment = field.Doc
+					}
+					if comment == nil {
+						continue
+					}
+
+					for _, name := range field.Names {
+						fields[name.Name] = joinLines(comment.Text())
+					}
+				}
+
+				comments[typeSpec.Name.Name] = fields
+			}
+		}
+	}
+
+	return comments
+}
+
+func joinLines(s string) string {
+	return string(bytes.Join(bytes.Fields([]byte(s)), []byte(" ")))
+}
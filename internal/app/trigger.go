@@ -0,0 +1,186 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/flohansen/documenter/internal/domain"
+)
+
+var (
+	// ErrSectionNotRunning is returned when triggering a section without a
+	// running scraper.
+	ErrSectionNotRunning = errors.New("section is not running")
+	// ErrSectionClaimed is returned when a triggered section is claimed by
+	// another replica, which scrapes it instead.
+	ErrSectionClaimed = errors.New("section is claimed by another replica")
+)
+
+// sectionTrigger requests immediate scrapes of a running section. The channel
+// buffers a single request, so requests arriving while one is pending are
+// merged into it. Waiters receive the outcome of the next triggered scrape.
+type sectionTrigger struct {
+	section SectionConfig
+	ch      chan struct{}
+	waiters []chan error
+	claimed bool // Whether this replica claimed the section in its last attempt
+}
+
+// registerTrigger registers a trigger for the running scraper of the section.
+func (i *Importer) registerTrigger(section SectionConfig) *sectionTrigger {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.triggers == nil {
+		i.triggers = make(map[domain.DocumentationKey]*sectionTrigger)
+	}
+
+	// Without a locker every section is claimed. Otherwise the section is
+	// claimed by its first scrape, which starts right away.
+	t := &sectionTrigger{section: section, ch: make(chan struct{}, 1), claimed: i.Locker == nil}
+	i.triggers[section.Key()] = t
+	return t
+}
+
+// setClaimed records whether this replica claimed the section in the last
+// attempt to scrape it.
+func (i *Importer) setClaimed(t *sectionTrigger, claimed bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	t.claimed = claimed
+}
+
+// unregisterTrigger removes the trigger of the section unless it was replaced
+// by the trigger of a restarted scraper in the meantime. Waiters are told
+// that the section is no longer running.
+func (i *Importer) unregisterTrigger(t *sectionTrigger) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.triggers[t.section.Key()] == t {
+		delete(i.triggers, t.section.Key())
+	}
+	for _, w := range t.waiters {
+		w <- ErrSectionNotRunning
+	}
+	t.waiters = nil
+}
+
+// trigger requests a scrape of the section. If waiter is set, it receives
+// the outcome of the scrape. The caller must hold i.mu.
+func (i *Importer) trigger(t *sectionTrigger, waiter chan error) {
+	if waiter != nil {
+		t.waiters = append(t.waiters, waiter)
+	}
+
+	select {
+	case t.ch <- struct{}{}:
+	default:
+	}
+}
+
+// takeWaiters returns the waiters of the next triggered scrape of the section.
+func (i *Importer) takeWaiters(t *sectionTrigger) []chan error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	waiters := t.waiters
+	t.waiters = nil
+	return waiters
+}
+
+// TriggerSection requests an immediate scrape of the running section with the
+// given qualified name (see domain.DocumentationKey.String) and waits for its
+// outcome. It returns ErrSectionNotRunning if no scraper runs the section and
+// ErrSectionClaimed if the section is scraped by another replica. Requests
+// for a section with a pending request are merged and share its outcome.
+func (i *Importer) TriggerSection(ctx context.Context, name string) error {
+	waiter := make(chan error, 1)
+
+	i.mu.Lock()
+	t, found := i.triggers[domain.ParseDocumentationKey(name)]
+	if found {
+		i.trigger(t, waiter)
+	}
+	i.mu.Unlock()
+
+	if !found {
+		return ErrSectionNotRunning
+	}
+
+	i.Logger.Info("triggered sections", "sections", []string{name})
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-waiter:
+		return err
+	}
+}
+
+// TriggerRepository requests an immediate scrape of every running section
+// whose URL points to the repository with any of the given URLs and returns
+// the qualified names of these sections. Sections claimed by another replica
+// are not triggered, as this replica would not scrape them, and are returned
+// separately. URLs are compared regardless of their scheme, credentials and
+// ".git" suffix. Requests for a section with a pending request are merged, so
+// bursts of pushes result in a single scrape.
+func (i *Importer) TriggerRepository(urls []string) (triggered, claimed []string) {
+	repos := make([]string, 0, len(urls))
+	for _, u := range urls {
+		if repo := normalizeRepositoryURL(u); len(repo) > 0 {
+			repos = append(repos, repo)
+		}
+	}
+
+	i.mu.Lock()
+	for key, t := range i.triggers {
+		if !slices.Contains(repos, normalizeRepositoryURL(t.section.URL)) {
+			continue
+		}
+
+		if !t.claimed {
+			claimed = append(claimed, key.String())
+			continue
+		}
+
+		i.trigger(t, nil)
+		triggered = append(triggered, key.String())
+	}
+	i.mu.Unlock()
+
+	slices.Sort(triggered)
+	slices.Sort(claimed)
+	if len(triggered) > 0 {
+		i.Logger.Info("triggered sections", "sections", triggered)
+	}
+
+	return triggered, claimed
+}
+
+// normalizeRepositoryURL returns the host and path of the repository URL in
+// lower case, e.g. "github.com/org/repo" for "git@github.com:org/repo.git"
+// and "https://github.com/Org/repo". It returns an empty string for invalid
+// URLs.
+func normalizeRepositoryURL(s string) string {
+	var host, path string
+	if scpLikeURL.MatchString(s) {
+		host, path, _ = strings.Cut(s[strings.Index(s, "@")+1:], ":")
+	} else {
+		u, err := url.Parse(s)
+		if err != nil || len(u.Host) == 0 {
+			return ""
+		}
+		host, path = u.Hostname(), u.Path
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if len(path) == 0 {
+		return ""
+	}
+
+	return strings.ToLower(host + "/" + path)
+}
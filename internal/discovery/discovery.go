@@ -0,0 +1,127 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// pageSize is the number of repositories requested per page.
+const pageSize = 50
+
+// defaultTimeout limits the duration of a single API request, so an
+// unresponsive API does not block the discovery until the next interval.
+const defaultTimeout = 30 * time.Second
+
+// Repository is a repository found in an organization.
+type Repository struct {
+	Name        string
+	Description string
+	CloneURL    string
+	SSHURL      string
+	Topics      []string
+	Archived    bool
+}
+
+// Client lists the repositories of an organization using the API of a Git
+// hosting service.
+type Client interface {
+	ListRepositories(ctx context.Context, organization string) ([]Repository, error)
+}
+
+// Filter selects the repositories to generate sections for.
+type Filter struct {
+	Topic           string         // Topic the repository must have (optional)
+	NamePattern     *regexp.Regexp // Pattern the repository name must match (optional)
+	IncludeArchived bool           // Whether archived repositories are included
+}
+
+// Match reports whether the repository passes the filter.
+func (f Filter) Match(repo Repository) bool {
+	if repo.Archived && !f.IncludeArchived {
+		return false
+	}
+	if len(f.Topic) > 0 && !slices.Contains(repo.Topics, f.Topic) {
+		return false
+	}
+	if f.NamePattern != nil && !f.NamePattern.MatchString(repo.Name) {
+		return false
+	}
+
+	return true
+}
+
+// apiClient implements the paginated requests shared by all providers.
+type apiClient struct {
+	httpClient *http.Client
+	baseURL    string
+	authHeader string
+	authValue  string
+}
+
+// listPages requests all pages of the endpoint built by path and decodes each
+// page using decode. Pagination stops at the first page with less than
+// pageSize entries.
+func (c apiClient) listPages(ctx context.Context, path func(page int) string, decode func(r io.Reader) (int, error)) error {
+	for page := 1; ; page++ {
+		n, err := c.get(ctx, path(page), decode)
+		if err != nil {
+			return err
+		}
+		if n < pageSize {
+			return nil
+		}
+	}
+}
+
+func (c apiClient) get(ctx context.Context, path string, decode func(r io.Reader) (int, error)) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(c.baseURL, "/")+path, nil)
+	if err != nil {
+		return 0, fmt.Errorf("could not create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if len(c.authValue) > 0 {
+		req.Header.Set(c.authHeader, c.authValue)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request error: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status code %d from %s", res.StatusCode, req.URL.Redacted())
+	}
+
+	n, err := decode(res.Body)
+	if err != nil {
+		return 0, fmt.Errorf("json decode error: %w", err)
+	}
+
+	return n, nil
+}
+
+// decodeInto returns a decode function appending the JSON array to repos
+// after converting each entry using convert.
+func decodeInto[T any](repos *[]Repository, convert func(T) Repository) func(r io.Reader) (int, error) {
+	return func(r io.Reader) (int, error) {
+		var entries []T
+		if err := json.NewDecoder(r).Decode(&entries); err != nil {
+			return 0, err
+		}
+
+		for _, entry := range entries {
+			*repos = append(*repos, convert(entry))
+		}
+
+		return len(entries), nil
+	}
+}
@@ -0,0 +1,167 @@
+package discovery_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/flohansen/documenter/internal/discovery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClients_ListRepositories(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		pageParam  string
+		authHeader string
+		authValue  string
+		entry      func(i int) map[string]any
+		client     func(baseURL string) discovery.Client
+	}{
+		{
+			name:       "github",
+			path:       "/orgs/my-org/repos",
+			pageParam:  "per_page",
+			authHeader: "Authorization",
+			authValue:  "Bearer secret",
+			entry: func(i int) map[string]any {
+				return map[string]any{
+					"name":      fmt.Sprintf("repo-%d", i),
+					"clone_url": fmt.Sprintf("https://example.com/repo-%d.git", i),
+					"ssh_url":   fmt.Sprintf("git@example.com:repo-%d.git", i),
+					"topics":    []string{"docs"},
+					"archived":  i%2 == 1,
+				}
+			},
+			client: func(baseURL string) discovery.Client {
+				return discovery.NewGitHubClient(baseURL, "secret")
+			},
+		},
+		{
+			name:       "gitea",
+			path:       "/api/v1/orgs/my-org/repos",
+			pageParam:  "limit",
+			authHeader: "Authorization",
+			authValue:  "token secret",
+			entry: func(i int) map[string]any {
+				return map[string]any{
+					"name":      fmt.Sprintf("repo-%d", i),
+					"clone_url": fmt.Sprintf("https://example.com/repo-%d.git", i),
+					"ssh_url":   fmt.Sprintf("git@example.com:repo-%d.git", i),
+					"topics":    []string{"docs"},
+					"archived":  i%2 == 1,
+				}
+			},
+			client: func(baseURL string) discovery.Client {
+				return discovery.NewGiteaClient(baseURL, "secret")
+			},
+		},
+		{
+			name:       "gitlab",
+			path:       "/api/v4/groups/my-org/projects",
+			pageParam:  "per_page",
+			authHeader: "PRIVATE-TOKEN",
+			authValue:  "secret",
+			entry: func(i int) map[string]any {
+				return map[string]any{
+					"path":             fmt.Sprintf("repo-%d", i),
+					"http_url_to_repo": fmt.Sprintf("https://example.com/repo-%d.git", i),
+					"ssh_url_to_repo":  fmt.Sprintf("git@example.com:repo-%d.git", i),
+					"topics":           []string{"docs"},
+					"archived":         i%2 == 1,
+				}
+			},
+			client: func(baseURL string) discovery.Client {
+				return discovery.NewGitLabClient(baseURL, "secret")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("should list repositories of all pages using "+tt.name, func(t *testing.T) {
+			// assign
+			const total = 60
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path || r.Header.Get(tt.authHeader) != tt.authValue {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+				size, _ := strconv.Atoi(r.URL.Query().Get(tt.pageParam))
+				entries := []map[string]any{}
+				for i := (page - 1) * size; i < min(page*size, total); i++ {
+					entries = append(entries, tt.entry(i))
+				}
+				json.NewEncoder(w).Encode(entries)
+			}))
+			defer server.Close()
+
+			// act
+			repos, err := tt.client(server.URL).ListRepositories(context.Background(), "my-org")
+
+			// assert
+			assert.NoError(t, err)
+			assert.Len(t, repos, total)
+			assert.Equal(t, discovery.Repository{
+				Name:     "repo-1",
+				CloneURL: "https://example.com/repo-1.git",
+				SSHURL:   "git@example.com:repo-1.git",
+				Topics:   []string{"docs"},
+				Archived: true,
+			}, repos[1])
+			assert.Equal(t, "repo-59", repos[59].Name)
+		})
+
+		t.Run("should return error on unexpected status using "+tt.name, func(t *testing.T) {
+			// assign
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			}))
+			defer server.Close()
+
+			// act
+			repos, err := tt.client(server.URL).ListRepositories(context.Background(), "my-org")
+
+			// assert
+			assert.ErrorContains(t, err, "unexpected status code 401")
+			assert.Empty(t, repos)
+		})
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	repo := discovery.Repository{Name: "service-docs", Topics: []string{"docs", "go"}}
+	archived := discovery.Repository{Name: "service-docs", Topics: []string{"docs"}, Archived: true}
+
+	tests := []struct {
+		name     string
+		filter   discovery.Filter
+		repo     discovery.Repository
+		expected bool
+	}{
+		{name: "should match everything with empty filter", repo: repo, expected: true},
+		{name: "should match topic", filter: discovery.Filter{Topic: "go"}, repo: repo, expected: true},
+		{name: "should not match missing topic", filter: discovery.Filter{Topic: "java"}, repo: repo, expected: false},
+		{name: "should match name pattern", filter: discovery.Filter{NamePattern: regexp.MustCompile(`-docs$`)}, repo: repo, expected: true},
+		{name: "should not match other names", filter: discovery.Filter{NamePattern: regexp.MustCompile(`^lib-`)}, repo: repo, expected: false},
+		{name: "should not match archived repositories", repo: archived, expected: false},
+		{name: "should match archived repositories if included", filter: discovery.Filter{IncludeArchived: true}, repo: archived, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			matched := tt.filter.Match(tt.repo)
+
+			// assert
+			assert.Equal(t, tt.expected, matched)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// GitHubClient lists repositories using the GitHub REST API.
+type GitHubClient struct {
+	api apiClient
+}
+
+// NewGitHubClient creates a client for the GitHub API located at baseURL,
+// e.g. https://api.github.com.
+func NewGitHubClient(baseURL, token string, opts ...ClientOption) *GitHubClient {
+	return &GitHubClient{api: newAPIClient(baseURL, "Authorization", bearer(token), opts)}
+}
+
+type gitHubRepository struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	CloneURL    string   `json:"clone_url"`
+	SSHURL      string   `json:"ssh_url"`
+	Topics      []string `json:"topics"`
+	Archived    bool     `json:"archived"`
+}
+
+func (c *GitHubClient) ListRepositories(ctx context.Context, organization string) ([]Repository, error) {
+	var repos []Repository
+	err := c.api.listPages(ctx, func(page int) string {
+		return fmt.Sprintf("/orgs/%s/repos?per_page=%d&page=%d", url.PathEscape(organization), pageSize, page)
+	}, decodeInto(&repos, func(r gitHubRepository) Repository {
+		return Repository{Name: r.Name, Description: r.Description, CloneURL: r.CloneURL, SSHURL: r.SSHURL, Topics: r.Topics, Archived: r.Archived}
+	}))
+
+	return repos, err
+}
+
+// GiteaClient lists repositories using the Gitea (and Forgejo) API.
+type GiteaClient struct {
+	api apiClient
+}
+
+// NewGiteaClient creates a client for the Gitea instance located at baseURL,
+// e.g. https://gitea.example.com.
+func NewGiteaClient(baseURL, token string, opts ...ClientOption) *GiteaClient {
+	authValue := ""
+	if len(token) > 0 {
+		authValue = "token " + token
+	}
+
+	return &GiteaClient{api: newAPIClient(baseURL, "Authorization", authValue, opts)}
+}
+
+type giteaRepository struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	CloneURL    string   `json:"clone_url"`
+	SSHURL      string   `json:"ssh_url"`
+	Topics      []string `json:"topics"`
+	Archived    bool     `json:"archived"`
+}
+
+func (c *GiteaClient) ListRepositories(ctx context.Context, organization string) ([]Repository, error) {
+	var repos []Repository
+	err := c.api.listPages(ctx, func(page int) string {
+		return fmt.Sprintf("/api/v1/orgs/%s/repos?limit=%d&page=%d", url.PathEscape(organization), pageSize, page)
+	}, decodeInto(&repos, func(r giteaRepository) Repository {
+		return Repository{Name: r.Name, Description: r.Description, CloneURL: r.CloneURL, SSHURL: r.SSHURL, Topics: r.Topics, Archived: r.Archived}
+	}))
+
+	return repos, err
+}
+
+// GitLabClient lists projects of a group using the GitLab REST API.
+type GitLabClient struct {
+	api apiClient
+}
+
+// NewGitLabClient creates a client for the GitLab instance located at
+// baseURL, e.g. https://gitlab.com.
+func NewGitLabClient(baseURL, token string, opts ...ClientOption) *GitLabClient {
+	return &GitLabClient{api: newAPIClient(baseURL, "PRIVATE-TOKEN", token, opts)}
+}
+
+type gitLabProject struct {
+	Path          string   `json:"path"`
+	Description   string   `json:"description"`
+	HTTPURLToRepo string   `json:"http_url_to_repo"`
+	SSHURLToRepo  string   `json:"ssh_url_to_repo"`
+	Topics        []string `json:"topics"`
+	Archived      bool     `json:"archived"`
+}
+
+// ListRepositories lists the projects of the group, including the projects
+// of its subgroups.
+func (c *GitLabClient) ListRepositories(ctx context.Context, group string) ([]Repository, error) {
+	var repos []Repository
+	err := c.api.listPages(ctx, func(page int) string {
+		return fmt.Sprintf("/api/v4/groups/%s/projects?include_subgroups=true&per_page=%d&page=%d", url.PathEscape(group), pageSize, page)
+	}, decodeInto(&repos, func(p gitLabProject) Repository {
+		return Repository{Name: p.Path, Description: p.Description, CloneURL: p.HTTPURLToRepo, SSHURL: p.SSHURLToRepo, Topics: p.Topics, Archived: p.Archived}
+	}))
+
+	return repos, err
+}
+
+func newAPIClient(baseURL, authHeader, authValue string, opts []ClientOption) apiClient {
+	c := apiClient{
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		baseURL:    baseURL,
+		authHeader: authHeader,
+		authValue:  authValue,
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return c
+}
+
+func bearer(token string) string {
+	if len(token) == 0 {
+		return ""
+	}
+
+	return "Bearer " + token
+}
+
+type ClientOption func(*apiClient)
+
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *apiClient) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
@@ -1,6 +1,127 @@
 package domain
 
+import (
+	"strings"
+	"time"
+)
+
+// DefaultNamespace is the namespace of documentations whose section does not
+// set one.
+const DefaultNamespace = "default"
+
 type Documentation struct {
-	Name    string
-	Content []byte
+	Namespace  string // Namespace of the documentation (DefaultNamespace if empty)
+	Name       string
+	Content    []byte
+	Metadata   Metadata
+	Timestamps Timestamps // Set by the repository, ignored on write
+}
+
+// Timestamps track when a documentation was written.
+type Timestamps struct {
+	CreatedAt     time.Time // First time the documentation was written
+	UpdatedAt     time.Time // Last time the content of the documentation changed
+	LastScrapedAt time.Time // Last time the documentation was written, even if unchanged
+}
+
+// Metadata describes a documentation, so it can be grouped and navigated.
+type Metadata struct {
+	Title       string
+	Description string
+	Owner       string // Owning team
+	Tags        []string
+	Category    string // Category path with "/" separated levels, e.g. "platform/payments"
+}
+
+// DocumentationKey identifies a documentation. Names are unique per
+// namespace.
+type DocumentationKey struct {
+	Namespace string
+	Name      string
+}
+
+// String returns the name qualified by its namespace in the form
+// "<namespace>/<name>". Documentations of the default namespace are
+// identified by their plain name, unless the name contains "/" and would be
+// mistaken for a qualified name, e.g. "default/org/repo". Namespaces cannot
+// contain "/", so qualified names are unique, see ParseDocumentationKey.
+func (k DocumentationKey) String() string {
+	namespace := k.Namespace
+	if len(namespace) == 0 {
+		namespace = DefaultNamespace
+	}
+
+	if namespace == DefaultNamespace && !strings.Contains(k.Name, "/") {
+		return k.Name
+	}
+
+	return namespace + "/" + k.Name
+}
+
+// ParseDocumentationKey returns the key of the qualified name returned by
+// DocumentationKey.String. Names without "/" belong to the default
+// namespace, otherwise the namespace is the part before the first "/".
+func ParseDocumentationKey(s string) DocumentationKey {
+	namespace, name, ok := strings.Cut(s, "/")
+	if !ok {
+		return DocumentationKey{Namespace: DefaultNamespace, Name: s}
+	}
+
+	return DocumentationKey{Namespace: namespace, Name: name}
+}
+
+// DocumentationFilter selects documentations by their metadata. Empty fields
+// match every documentation, except for the namespace which defaults to
+// DefaultNamespace, so documentations of other namespaces are never mixed in.
+type DocumentationFilter struct {
+	Namespace string
+	Owner     string
+	Tags      []string // Tags a documentation must all have
+	Category  string   // Category including its subcategories
+}
+
+const (
+	// DefaultPageLimit is the number of documentations per page if a page
+	// does not set a limit.
+	DefaultPageLimit = 50
+	// MaxPageLimit is the largest number of documentations per page.
+	MaxPageLimit = 500
+)
+
+// DocumentationSort defines the order of paginated documentations.
+type DocumentationSort int
+
+const (
+	// SortByName orders documentations by name
+	SortByName DocumentationSort = iota
+	// SortByUpdated orders documentations by the time their content changed,
+	// most recently updated first
+	SortByUpdated
+)
+
+// Page selects a page of documentations. Pages are continued by cursor
+// instead of offset, so documentations written between two requests are
+// neither skipped nor returned twice.
+type Page struct {
+	Sort   DocumentationSort
+	Limit  int    // Maximum number of documentations (DefaultPageLimit if 0, at most MaxPageLimit)
+	Cursor string // NextCursor of the previous page (empty for the first page)
+}
+
+// DocumentationPage is a page of documentations.
+type DocumentationPage struct {
+	Documentations []Documentation
+	NextCursor     string // Cursor of the next page (empty for the last page)
+}
+
+// DocumentationChange describes a documentation whose content changed.
+type DocumentationChange struct {
+	Namespace string
+	Name      string
+	Hash      string // Hex encoded SHA-256 hash of the new content
+}
+
+// Key returns the key of the changed documentation.
+func (c DocumentationChange) Key() DocumentationKey {
+	return DocumentationKey{Namespace: c.Namespace, Name: c.Name}
 }
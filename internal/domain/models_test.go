@@ -0,0 +1,33 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/flohansen/documenter/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocumentationKey_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      domain.DocumentationKey
+		expected string
+	}{
+		{name: "should return plain name of default namespace", key: domain.DocumentationKey{Name: "docs"}, expected: "docs"},
+		{name: "should qualify name of other namespace", key: domain.DocumentationKey{Namespace: "team", Name: "docs"}, expected: "team/docs"},
+		{name: "should qualify default name containing slash", key: domain.DocumentationKey{Namespace: domain.DefaultNamespace, Name: "team/docs"}, expected: "default/team/docs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			s := tt.key.String()
+			key := domain.ParseDocumentationKey(s)
+
+			// assert
+			assert.Equal(t, tt.expected, s)
+			assert.Equal(t, tt.key.Name, key.Name)
+			assert.Equal(t, tt.key.String(), key.String())
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/flohansen/documenter/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "documenter"
+
+// Metrics collects the metrics of the importer in its own registry, so
+// multiple instances (e.g. in tests) do not conflict.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	compressionRatio *prometheus.HistogramVec
+	rawBytes         *prometheus.CounterVec
+	storedBytes      *prometheus.CounterVec
+}
+
+// New creates the metrics and registers them together with the Go runtime
+// and process collectors.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		compressionRatio: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "content_compression_ratio",
+			Help:      "Ratio of the raw to the stored size of written documentation contents.",
+			Buckets:   []float64{1, 1.5, 2, 3, 4, 6, 8, 12, 16},
+		}, []string{"codec"}),
+		rawBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "content_raw_bytes_total",
+			Help:      "Total size of written documentation contents before encoding.",
+		}, []string{"codec"}),
+		storedBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "content_stored_bytes_total",
+			Help:      "Total size of written documentation contents after encoding.",
+		}, []string{"codec"}),
+	}
+
+	m.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		m.compressionRatio,
+		m.rawBytes,
+		m.storedBytes,
+	)
+
+	return m
+}
+
+// ObserveCompression implements repository.CompressionObserver. The ratio of
+// empty contents is not observed.
+func (m *Metrics) ObserveCompression(codec repository.Codec, rawSize, storedSize int) {
+	m.rawBytes.WithLabelValues(codec.String()).Add(float64(rawSize))
+	m.storedBytes.WithLabelValues(codec.String()).Add(float64(storedSize))
+	if rawSize > 0 && storedSize > 0 {
+		m.compressionRatio.WithLabelValues(codec.String()).Observe(float64(rawSize) / float64(storedSize))
+	}
+}
+
+// Handler returns the HTTP handler exposing the metrics in the Prometheus
+// text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
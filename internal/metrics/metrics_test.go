@@ -0,0 +1,50 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flohansen/documenter/internal/metrics"
+	"github.com/flohansen/documenter/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_ObserveCompression(t *testing.T) {
+	t.Run("should expose compression sizes and ratios per codec", func(t *testing.T) {
+		// assign
+		m := metrics.New()
+
+		// act
+		m.ObserveCompression(repository.CodecZstd, 400, 100)
+		m.ObserveCompression(repository.CodecZstd, 200, 100)
+		m.ObserveCompression(repository.CodecNone, 50, 50)
+
+		// assert
+		rec := httptest.NewRecorder()
+		m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		body, _ := io.ReadAll(rec.Body)
+
+		assert.Contains(t, string(body), `documenter_content_raw_bytes_total{codec="zstd"} 600`)
+		assert.Contains(t, string(body), `documenter_content_stored_bytes_total{codec="zstd"} 200`)
+		assert.Contains(t, string(body), `documenter_content_compression_ratio_sum{codec="zstd"} 6`)
+		assert.Contains(t, string(body), `documenter_content_compression_ratio_count{codec="zstd"} 2`)
+		assert.Contains(t, string(body), `documenter_content_compression_ratio_bucket{codec="none",le="1"} 1`)
+	})
+
+	t.Run("should not observe the ratio of empty contents", func(t *testing.T) {
+		// assign
+		m := metrics.New()
+
+		// act
+		m.ObserveCompression(repository.CodecGzip, 0, 20)
+
+		// assert
+		rec := httptest.NewRecorder()
+		m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		body, _ := io.ReadAll(rec.Body)
+
+		assert.Contains(t, string(body), `documenter_content_stored_bytes_total{codec="gzip"} 20`)
+		assert.NotContains(t, string(body), `documenter_content_compression_ratio_count{codec="gzip"}`)
+	})
+}
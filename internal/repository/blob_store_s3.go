@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// ErrBlobNotFound is returned if a blob does not exist.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore stores binary objects like documentation contents by key.
+type BlobStore interface {
+	PutBlob(ctx context.Context, key string, data []byte) error
+	// GetBlob returns the blob with the given key or ErrBlobNotFound.
+	GetBlob(ctx context.Context, key string) ([]byte, error)
+	// DeleteBlob deletes the blob with the given key. Deleting a missing blob
+	// is not an error.
+	DeleteBlob(ctx context.Context, key string) error
+}
+
+// BlobStoreS3 stores blobs in a bucket of an S3-compatible object storage,
+// e.g. AWS S3 or MinIO.
+type BlobStoreS3 struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+type blobStoreOptions struct {
+	accessKey string
+	secretKey string
+	region    string
+	prefix    string
+	insecure  bool
+}
+
+type BlobStoreOption func(*blobStoreOptions)
+
+// WithS3Credentials sets the static credentials used to sign requests.
+func WithS3Credentials(accessKey, secretKey string) BlobStoreOption {
+	return func(o *blobStoreOptions) {
+		o.accessKey = accessKey
+		o.secretKey = secretKey
+	}
+}
+
+// WithS3Region sets the region of the bucket.
+func WithS3Region(region string) BlobStoreOption {
+	return func(o *blobStoreOptions) {
+		o.region = region
+	}
+}
+
+// WithS3Prefix sets the prefix prepended to the keys of all blobs.
+func WithS3Prefix(prefix string) BlobStoreOption {
+	return func(o *blobStoreOptions) {
+		o.prefix = prefix
+	}
+}
+
+// WithS3Insecure uses plain HTTP instead of HTTPS.
+func WithS3Insecure(insecure bool) BlobStoreOption {
+	return func(o *blobStoreOptions) {
+		o.insecure = insecure
+	}
+}
+
+// NewBlobStoreS3 creates a blob store for the bucket of the object storage
+// located at endpoint (host:port). The bucket must exist.
+func NewBlobStoreS3(endpoint, bucket string, opts ...BlobStoreOption) (*BlobStoreS3, error) {
+	var o blobStoreOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(o.accessKey, o.secretKey, ""),
+		Secure: !o.insecure,
+		Region: o.region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create object storage client: %w", err)
+	}
+
+	return &BlobStoreS3{
+		client: client,
+		bucket: bucket,
+		prefix: o.prefix,
+	}, nil
+}
+
+// Ping checks that the bucket exists and is accessible.
+func (s *BlobStoreS3) Ping(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return fmt.Errorf("could not access bucket %s: %w", s.bucket, err)
+	}
+	if !exists {
+		return fmt.Errorf("bucket %s does not exist", s.bucket)
+	}
+
+	return nil
+}
+
+func (s *BlobStoreS3) PutBlob(ctx context.Context, key string, data []byte) error {
+	_, err := s.client.PutObject(ctx, s.bucket, s.objectName(key), bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{})
+	return err
+}
+
+func (s *BlobStoreS3) GetBlob(ctx context.Context, key string) ([]byte, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, s.objectName(key), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	data, err := io.ReadAll(obj)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, key)
+		}
+
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (s *BlobStoreS3) DeleteBlob(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, s.objectName(key), minio.RemoveObjectOptions{})
+}
+
+func (s *BlobStoreS3) objectName(key string) string {
+	if len(s.prefix) == 0 {
+		return key
+	}
+
+	return path.Join(s.prefix, key)
+}
@@ -0,0 +1,67 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flohansen/documenter/internal/repository"
+	"github.com/flohansen/documenter/test/testhelpers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlobStoreS3_Integration(t *testing.T) {
+	container := testhelpers.StartMinioContainer(t, testhelpers.WithBucket("docs"))
+
+	newStore := func(t *testing.T, bucket string) *repository.BlobStoreS3 {
+		store, err := repository.NewBlobStoreS3(container.Endpoint(), bucket,
+			repository.WithS3Credentials(testhelpers.MinioAccessKey, testhelpers.MinioSecretKey),
+			repository.WithS3Prefix("documenter"),
+			repository.WithS3Insecure(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return store
+	}
+
+	t.Run("should store and read blobs", func(t *testing.T) {
+		// assign
+		store := newStore(t, "docs")
+
+		// act
+		err := store.PutBlob(context.Background(), "default/name/abc", []byte("content"))
+
+		// assert
+		assert.NoError(t, err)
+		data, err := store.GetBlob(context.Background(), "default/name/abc")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("content"), data)
+	})
+
+	t.Run("should delete blobs", func(t *testing.T) {
+		// assign
+		store := newStore(t, "docs")
+		if err := store.PutBlob(context.Background(), "default/remove/abc", []byte("content")); err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		err := store.DeleteBlob(context.Background(), "default/remove/abc")
+
+		// assert
+		assert.NoError(t, err)
+		_, err = store.GetBlob(context.Background(), "default/remove/abc")
+		assert.ErrorIs(t, err, repository.ErrBlobNotFound)
+	})
+
+	t.Run("should return error if bucket does not exist", func(t *testing.T) {
+		// assign
+		store := newStore(t, "missing")
+
+		// act
+		err := store.Ping(context.Background())
+
+		// assert
+		assert.ErrorContains(t, err, "bucket missing does not exist")
+	})
+}
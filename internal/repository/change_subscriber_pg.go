@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/flohansen/documenter/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ChangesChannel is the PostgreSQL notification channel a trigger on the
+// documentations table notifies whenever the content of a documentation is
+// inserted or changed. The payload is a JSON object with the keys namespace,
+// name and hash.
+const ChangesChannel = "documentation_changes"
+
+// ChangeSubscriberPostgres subscribes to the changes of documentations using
+// PostgreSQL LISTEN/NOTIFY, so consumers do not have to poll the table.
+type ChangeSubscriberPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewChangeSubscriberPostgres(pool *pgxpool.Pool) *ChangeSubscriberPostgres {
+	return &ChangeSubscriberPostgres{pool: pool}
+}
+
+// Subscribe takes a dedicated connection out of the pool and listens for
+// changes on it. Changes are received from the moment Subscribe returns until
+// the subscription is closed. Notifications are only delivered while the
+// connection is alive, so subscribers should resynchronize after reconnecting.
+func (s *ChangeSubscriberPostgres) Subscribe(ctx context.Context) (*ChangeSubscription, error) {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not acquire listen connection: %w", err)
+	}
+
+	sub := &ChangeSubscription{conn: conn.Hijack()}
+	if _, err := sub.conn.Exec(ctx, "LISTEN "+pgx.Identifier{ChangesChannel}.Sanitize()); err != nil {
+		sub.Close(context.Background())
+		return nil, fmt.Errorf("could not listen for changes: %w", err)
+	}
+
+	return sub, nil
+}
+
+// ChangeSubscription receives the changes of documentations. It must not be
+// used concurrently.
+type ChangeSubscription struct {
+	conn *pgx.Conn
+}
+
+// changePayload is the payload of notifications on ChangesChannel.
+type changePayload struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Hash      string `json:"hash"`
+}
+
+// Next blocks until the next change is received or ctx is done. Errors of
+// the connection are returned, after which the subscription has to be closed.
+func (s *ChangeSubscription) Next(ctx context.Context) (domain.DocumentationChange, error) {
+	n, err := s.conn.WaitForNotification(ctx)
+	if err != nil {
+		return domain.DocumentationChange{}, err
+	}
+
+	var payload changePayload
+	if err := json.Unmarshal([]byte(n.Payload), &payload); err != nil {
+		return domain.DocumentationChange{}, fmt.Errorf("could not decode change %q: %w", n.Payload, err)
+	}
+
+	return domain.DocumentationChange(payload), nil
+}
+
+// Close ends the subscription by closing its connection.
+func (s *ChangeSubscription) Close(ctx context.Context) error {
+	return s.conn.Close(ctx)
+}
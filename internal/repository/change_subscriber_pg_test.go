@@ -0,0 +1,123 @@
+package repository_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/flohansen/documenter/internal/domain"
+	"github.com/flohansen/documenter/internal/repository"
+	"github.com/flohansen/documenter/test/testhelpers"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangeSubscriberPostgres_Integration(t *testing.T) {
+	container := testhelpers.StartPostgresContainer(t, testhelpers.WithMigration("../../sql/migrations"))
+
+	pool, err := pgxpool.New(context.Background(), container.Dsn())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	repo := repository.NewDocRepoPostgres(pool, repository.WithCompression(repository.CodecZstd))
+	upsert := func(doc domain.Documentation) {
+		if _, err := repo.UpsertDocumentation(context.Background(), doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hash := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(sum[:])
+	}
+
+	subscribe := func(t *testing.T) *repository.ChangeSubscription {
+		sub, err := repository.NewChangeSubscriberPostgres(pool).Subscribe(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { sub.Close(context.Background()) })
+		return sub
+	}
+	next := func(t *testing.T, sub *repository.ChangeSubscription) domain.DocumentationChange {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		change, err := sub.Next(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return change
+	}
+
+	t.Run("should notify about new documentations", func(t *testing.T) {
+		// assign
+		sub := subscribe(t)
+
+		// act
+		upsert(domain.Documentation{Namespace: "team", Name: "new", Content: []byte("content")})
+
+		// assert
+		assert.Equal(t, domain.DocumentationChange{Namespace: "team", Name: "new", Hash: hash("content")}, next(t, sub))
+	})
+
+	t.Run("should notify only if content changed", func(t *testing.T) {
+		// assign
+		upsert(domain.Documentation{Name: "unchanged", Content: []byte("content")})
+		upsert(domain.Documentation{Name: "changed", Content: []byte("change me")})
+		sub := subscribe(t)
+
+		// act
+		upsert(domain.Documentation{Name: "unchanged", Content: []byte("content"), Metadata: domain.Metadata{Title: "Title"}})
+		upsert(domain.Documentation{Name: "changed", Content: []byte("content")})
+
+		// assert
+		assert.Equal(t, domain.DocumentationChange{Namespace: domain.DefaultNamespace, Name: "changed", Hash: hash("content")}, next(t, sub))
+	})
+
+	t.Run("should not notify about unchanged contents stored without hash", func(t *testing.T) {
+		// assign
+		upsert(domain.Documentation{Name: "unhashed", Content: []byte("content")})
+		upsert(domain.Documentation{Name: "unhashed-changed", Content: []byte("change me")})
+		if _, err := pool.Exec(context.Background(), "UPDATE documentations SET content_hash = '' WHERE name LIKE 'unhashed%'"); err != nil {
+			t.Fatal(err)
+		}
+		sub := subscribe(t)
+
+		// act
+		upsert(domain.Documentation{Name: "unhashed", Content: []byte("content")})
+		upsert(domain.Documentation{Name: "unhashed-changed", Content: []byte("content")})
+
+		// assert
+		assert.Equal(t, domain.DocumentationChange{Namespace: domain.DefaultNamespace, Name: "unhashed-changed", Hash: hash("content")}, next(t, sub))
+	})
+
+	t.Run("should notify every subscriber", func(t *testing.T) {
+		// assign
+		a := subscribe(t)
+		b := subscribe(t)
+
+		// act
+		upsert(domain.Documentation{Name: "shared", Content: []byte("shared")})
+
+		// assert
+		assert.Equal(t, "shared", next(t, a).Name)
+		assert.Equal(t, "shared", next(t, b).Name)
+	})
+
+	t.Run("should return error when context is done", func(t *testing.T) {
+		// assign
+		sub := subscribe(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// act
+		_, err := sub.Next(ctx)
+
+		// assert
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Codec identifies how a stored content is encoded. The codec is stored next
+// to each content, so contents written with another codec stay readable.
+type Codec string
+
+const (
+	CodecNone Codec = ""     // Uncompressed content
+	CodecGzip Codec = "gzip" // Content compressed with gzip
+	CodecZstd Codec = "zstd" // Content compressed with Zstandard
+)
+
+// String returns the name of the codec as used in metrics.
+func (c Codec) String() string {
+	if c == CodecNone {
+		return "none"
+	}
+
+	return string(c)
+}
+
+// zstd encoders and decoders are safe for concurrent use of EncodeAll and
+// DecodeAll and expensive to create, so they are shared.
+var (
+	zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) { return zstd.NewWriter(nil) })
+	zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) { return zstd.NewReader(nil) })
+)
+
+// Encode compresses the data using the codec.
+func (c Codec) Encode(data []byte) ([]byte, error) {
+	switch c {
+	case CodecNone:
+		return data, nil
+	case CodecGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	case CodecZstd:
+		enc, err := zstdEncoder()
+		if err != nil {
+			return nil, err
+		}
+
+		return enc.EncodeAll(data, nil), nil
+	default:
+		return nil, fmt.Errorf("unknown codec %q", string(c))
+	}
+}
+
+// Decode decompresses the data encoded using the codec.
+func (c Codec) Decode(data []byte) ([]byte, error) {
+	switch c {
+	case CodecNone:
+		return data, nil
+	case CodecGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+
+		return io.ReadAll(r)
+	case CodecZstd:
+		dec, err := zstdDecoder()
+		if err != nil {
+			return nil, err
+		}
+
+		return dec.DecodeAll(data, nil)
+	default:
+		return nil, fmt.Errorf("unknown codec %q", string(c))
+	}
+}
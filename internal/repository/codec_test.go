@@ -0,0 +1,41 @@
+package repository_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/flohansen/documenter/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodec(t *testing.T) {
+	content := bytes.Repeat([]byte("# Documentation\n\nSome content.\n"), 100)
+
+	for _, codec := range []repository.Codec{repository.CodecNone, repository.CodecGzip, repository.CodecZstd} {
+		t.Run("should decode encoded content using "+codec.String(), func(t *testing.T) {
+			// act
+			encoded, err := codec.Encode(content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := codec.Decode(encoded)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, content, decoded)
+			if codec != repository.CodecNone {
+				assert.Less(t, len(encoded), len(content))
+			}
+		})
+	}
+
+	t.Run("should return error for unknown codec", func(t *testing.T) {
+		// act
+		_, encodeErr := repository.Codec("lz4").Encode(content)
+		_, decodeErr := repository.Codec("lz4").Decode(content)
+
+		// assert
+		assert.EqualError(t, encodeErr, `unknown codec "lz4"`)
+		assert.EqualError(t, decodeErr, `unknown codec "lz4"`)
+	})
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SQLiteScheme is the scheme of connection strings selecting the SQLite
+// backend, e.g. sqlite://documenter.db or sqlite:///var/lib/documenter.db.
+const SQLiteScheme = "sqlite://"
+
+// IsSQLiteDSN reports whether the connection string selects the SQLite
+// backend.
+func IsSQLiteDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, SQLiteScheme)
+}
+
+// OpenSQLite opens the SQLite database file of the connection string, which
+// is created if it does not exist. Query parameters are passed to the driver,
+// e.g. sqlite://documenter.db?_busy_timeout=5000.
+//
+// SQLite allows a single writer at a time, so the returned database uses a
+// single connection to serialize concurrent scrapers instead of failing with
+// "database is locked" errors.
+func OpenSQLite(dsn string) (*sql.DB, error) {
+	if !IsSQLiteDSN(dsn) {
+		return nil, fmt.Errorf("invalid sqlite dsn, must start with %q", SQLiteScheme)
+	}
+
+	path := strings.TrimPrefix(dsn, SQLiteScheme)
+	if len(path) == 0 || strings.HasPrefix(path, "?") {
+		return nil, fmt.Errorf("invalid sqlite dsn, missing database file")
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open sqlite database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	return db, nil
+}
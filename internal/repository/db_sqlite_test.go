@@ -0,0 +1,56 @@
+package repository_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/flohansen/documenter/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSQLiteDSN(t *testing.T) {
+	tests := []struct {
+		dsn      string
+		expected bool
+	}{
+		{dsn: "sqlite://documenter.db", expected: true},
+		{dsn: "sqlite:///var/lib/documenter.db?_busy_timeout=5000", expected: true},
+		{dsn: "postgres://user@db:5432/docs", expected: false},
+		{dsn: "host=db user=user", expected: false},
+		{dsn: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dsn, func(t *testing.T) {
+			// act
+			ok := repository.IsSQLiteDSN(tt.dsn)
+
+			// assert
+			assert.Equal(t, tt.expected, ok)
+		})
+	}
+}
+
+func TestOpenSQLite(t *testing.T) {
+	t.Run("should create database file", func(t *testing.T) {
+		// assign
+		path := filepath.Join(t.TempDir(), "documenter.db")
+
+		// act
+		db, err := repository.OpenSQLite("sqlite://" + path + "?_busy_timeout=5000")
+
+		// assert
+		assert.NoError(t, err)
+		defer db.Close()
+		assert.NoError(t, db.Ping())
+		assert.FileExists(t, path)
+	})
+
+	t.Run("should return error if database file is missing", func(t *testing.T) {
+		// act
+		_, err := repository.OpenSQLite("sqlite://?_busy_timeout=5000")
+
+		// assert
+		assert.ErrorContains(t, err, "missing database file")
+	})
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/flohansen/documenter/internal/domain"
+)
+
+// DocumentationReader reads the documentations written by the importer, e.g.
+// to serve them. It is implemented by the repositories of all backends.
+type DocumentationReader interface {
+	GetDocumentation(ctx context.Context, key domain.DocumentationKey) (domain.Documentation, error)
+	ListDocumentations(ctx context.Context, filter domain.DocumentationFilter) ([]domain.Documentation, error)
+	ListDocumentationsPage(ctx context.Context, filter domain.DocumentationFilter, page domain.Page) (domain.DocumentationPage, error)
+	CountDocumentations(ctx context.Context, filter domain.DocumentationFilter) (int64, error)
+}
+
+var (
+	_ DocumentationReader = (*DocRepoPostgres)(nil)
+	_ DocumentationReader = (*DocRepoSQLite)(nil)
+	_ DocumentationReader = (*DocRepoFilesystem)(nil)
+)
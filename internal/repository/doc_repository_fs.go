@@ -0,0 +1,604 @@
+package repository
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flohansen/documenter/internal/domain"
+)
+
+const (
+	// FilesystemScheme is the scheme of connection strings selecting the
+	// filesystem backend, e.g. file://docs or file:///var/lib/documenter.
+	FilesystemScheme = "file://"
+
+	// ManifestFile is the name of the index manifest in the root directory.
+	ManifestFile = "index.json"
+
+	// archiveDir is the directory of archived documentations. Namespaces
+	// cannot start with a dot, so it never collides with a namespace.
+	archiveDir = ".archive"
+
+	// archiveTimeFormat formats the time a documentation was archived, which
+	// is part of its path in the archive.
+	archiveTimeFormat = "20060102T150405.000000000Z"
+)
+
+// Manifest indexes the documentations written by DocRepoFilesystem.
+type Manifest struct {
+	Documentations []ManifestEntry `json:"documentations"`
+}
+
+// ManifestEntry describes a documentation and the location of its content.
+type ManifestEntry struct {
+	Namespace   string   `json:"namespace"`
+	Name        string   `json:"name"`
+	Section     string   `json:"section"` // Name of the section the documentation was written for
+	Path        string   `json:"path"`    // Slash separated path relative to the root directory
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Owner       string   `json:"owner,omitempty"`
+	Tags        []string `json:"tags"`
+	Category    string   `json:"category,omitempty"`
+	Stale       bool     `json:"stale,omitempty"`
+
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`     // Last change of the content
+	LastScrapedAt time.Time `json:"lastScrapedAt"` // Last write, even if the content was unchanged
+}
+
+// section returns the section of the documentation. Entries written before
+// sections were recorded belong to the section with the same name.
+func (e ManifestEntry) section() string {
+	return cmp.Or(e.Section, e.Name)
+}
+
+// sectionKey returns the key of the section the documentation belongs to.
+func (e ManifestEntry) sectionKey() domain.DocumentationKey {
+	return domain.DocumentationKey{Namespace: e.Namespace, Name: e.section()}
+}
+
+// IsFilesystemDSN reports whether the connection string selects the
+// filesystem backend.
+func IsFilesystemDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, FilesystemScheme)
+}
+
+// DocRepoFilesystem writes each documentation as Markdown file to
+// <root>/<namespace>/<name>.md and indexes all documentations in the manifest
+// <root>/index.json, so the directory can be fed to static site generators.
+// Files are written to a temporary file first and renamed afterwards, so
+// readers never see partially written documentations.
+type DocRepoFilesystem struct {
+	root string
+
+	mu      sync.Mutex
+	entries map[domain.DocumentationKey]ManifestEntry
+}
+
+// NewDocRepoFilesystem creates the repository for the root directory of the
+// connection string (or a plain path), which is created if it does not exist.
+// An existing manifest is loaded, so documentations written by a previous run
+// can be pruned.
+func NewDocRepoFilesystem(dsn string) (*DocRepoFilesystem, error) {
+	root := strings.TrimPrefix(dsn, FilesystemScheme)
+	if len(root) == 0 {
+		return nil, fmt.Errorf("invalid filesystem dsn, missing directory")
+	}
+	root = filepath.Clean(root)
+
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create directory: %w", err)
+	}
+
+	r := &DocRepoFilesystem{
+		root:    root,
+		entries: make(map[domain.DocumentationKey]ManifestEntry),
+	}
+
+	b, err := os.ReadFile(filepath.Join(root, ManifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, fmt.Errorf("could not decode manifest: %w", err)
+	}
+	for _, entry := range manifest.Documentations {
+		r.entries[domain.DocumentationKey{Namespace: entry.Namespace, Name: entry.Name}] = entry
+	}
+
+	return r, nil
+}
+
+// UpsertDocumentation writes the documentation as the single documentation
+// of the section with the same name, unless its file already has the same
+// content, in which case only the manifest entry is updated and the update
+// time is kept. It reports whether the documentation was created or its
+// content changed.
+func (r *DocRepoFilesystem) UpsertDocumentation(ctx context.Context, doc domain.Documentation) (bool, error) {
+	p, err := documentationPath(namespaceOrDefault(doc.Namespace), doc.Name)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changed, err := r.upsert(doc.Name, doc, p, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+
+	return changed, r.writeManifest()
+}
+
+// UpsertSectionDocumentations writes all documentations of the section and
+// deletes the section's documentations which are not part of docs. The
+// manifest is written once afterwards, so readers of the manifest never see a
+// partially updated section. The documentations must belong to the namespace
+// of the section. It reports whether any documentation was created, changed
+// or deleted.
+func (r *DocRepoFilesystem) UpsertSectionDocumentations(ctx context.Context, section domain.DocumentationKey, docs []domain.Documentation) (bool, error) {
+	namespace := namespaceOrDefault(section.Namespace)
+	paths := make([]string, 0, len(docs))
+	keep := make(map[domain.DocumentationKey]bool, len(docs))
+	for _, doc := range docs {
+		if namespaceOrDefault(doc.Namespace) != namespace {
+			return false, fmt.Errorf("documentation %s does not belong to section %s", domain.DocumentationKey{Namespace: doc.Namespace, Name: doc.Name}, section)
+		}
+
+		p, err := documentationPath(namespace, doc.Name)
+		if err != nil {
+			return false, err
+		}
+		paths = append(paths, p)
+		keep[domain.DocumentationKey{Namespace: namespace, Name: doc.Name}] = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+	var changed bool
+	for n, doc := range docs {
+		c, err := r.upsert(section.Name, doc, paths[n], now)
+		if err != nil {
+			return false, fmt.Errorf("could not upsert %s: %w", doc.Name, err)
+		}
+		changed = changed || c
+	}
+
+	for key, entry := range r.entries {
+		if key.Namespace != namespace || entry.section() != section.Name || keep[key] {
+			continue
+		}
+
+		if err := r.removeFile(entry.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return false, fmt.Errorf("could not remove %s: %w", key, err)
+		}
+		delete(r.entries, key)
+		changed = true
+	}
+
+	if err := r.writeManifest(); err != nil {
+		return false, err
+	}
+
+	return changed, nil
+}
+
+// upsert writes the documentation of the section to the file at the slash
+// separated path p, unless the file already has the same content, and
+// updates its manifest entry. The caller must hold r.mu and write the
+// manifest afterwards.
+func (r *DocRepoFilesystem) upsert(section string, doc domain.Documentation, p string, now time.Time) (bool, error) {
+	namespace := namespaceOrDefault(doc.Namespace)
+	key := domain.DocumentationKey{Namespace: namespace, Name: doc.Name}
+	name := filepath.Join(r.root, filepath.FromSlash(p))
+	prev, exists := r.entries[key]
+
+	updatedAt := now
+	changed := true
+	if current, err := os.ReadFile(name); exists && err == nil && bytes.Equal(current, doc.Content) {
+		updatedAt = prev.UpdatedAt
+		changed = false
+	} else if err := writeFileAtomic(name, doc.Content); err != nil {
+		return false, fmt.Errorf("could not write documentation: %w", err)
+	}
+
+	tags := doc.Metadata.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	r.entries[key] = ManifestEntry{
+		Namespace:     namespace,
+		Name:          doc.Name,
+		Section:       section,
+		Path:          p,
+		Title:         doc.Metadata.Title,
+		Description:   doc.Metadata.Description,
+		Owner:         doc.Metadata.Owner,
+		Tags:          tags,
+		Category:      doc.Metadata.Category,
+		CreatedAt:     cmp.Or(prev.CreatedAt, now),
+		UpdatedAt:     cmp.Or(updatedAt, now),
+		LastScrapedAt: now,
+	}
+
+	return changed, nil
+}
+
+// GetDocumentation returns the documentation with the key or
+// ErrDocumentationNotFound.
+func (r *DocRepoFilesystem) GetDocumentation(ctx context.Context, key domain.DocumentationKey) (domain.Documentation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[domain.DocumentationKey{Namespace: namespaceOrDefault(key.Namespace), Name: key.Name}]
+	if !ok {
+		return domain.Documentation{}, ErrDocumentationNotFound
+	}
+
+	return r.documentation(entry)
+}
+
+// ListDocumentations returns all documentations of the filter's namespace
+// matching the filter ordered by category and name.
+func (r *DocRepoFilesystem) ListDocumentations(ctx context.Context, filter domain.DocumentationFilter) ([]domain.Documentation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := r.matchingEntries(filter)
+	slices.SortFunc(entries, func(a, b ManifestEntry) int {
+		return cmp.Or(strings.Compare(a.Category, b.Category), strings.Compare(a.Name, b.Name))
+	})
+
+	return r.documentations(entries)
+}
+
+// ListDocumentationsPage returns a page of the documentations of the
+// filter's namespace matching the filter. It returns ErrInvalidCursor if the
+// cursor of the page was not returned for the same sort order.
+func (r *DocRepoFilesystem) ListDocumentationsPage(ctx context.Context, filter domain.DocumentationFilter, page domain.Page) (domain.DocumentationPage, error) {
+	cursor, err := parsePageCursor(page.Cursor, page.Sort)
+	if err != nil {
+		return domain.DocumentationPage{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := r.matchingEntries(filter)
+	switch page.Sort {
+	case domain.SortByName:
+		slices.SortFunc(entries, func(a, b ManifestEntry) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+		entries = slices.DeleteFunc(entries, func(entry ManifestEntry) bool {
+			return entry.Name <= cursor.Name
+		})
+	case domain.SortByUpdated:
+		slices.SortFunc(entries, func(a, b ManifestEntry) int {
+			return cmp.Or(b.UpdatedAt.Compare(a.UpdatedAt), strings.Compare(b.Name, a.Name))
+		})
+		if !cursor.UpdatedAt.IsZero() {
+			entries = slices.DeleteFunc(entries, func(entry ManifestEntry) bool {
+				c := entry.UpdatedAt.Compare(cursor.UpdatedAt)
+				return c > 0 || (c == 0 && entry.Name >= cursor.Name)
+			})
+		}
+	default:
+		return domain.DocumentationPage{}, fmt.Errorf("unknown sort order %d", page.Sort)
+	}
+
+	var result domain.DocumentationPage
+	if limit := pageLimit(page); len(entries) > limit {
+		entries = entries[:limit]
+		last := entries[limit-1]
+		result.NextCursor = pageCursor{Sort: page.Sort, Name: last.Name, UpdatedAt: last.UpdatedAt}.String()
+	}
+
+	result.Documentations, err = r.documentations(entries)
+	if err != nil {
+		return domain.DocumentationPage{}, err
+	}
+
+	return result, nil
+}
+
+// CountDocumentations returns the number of documentations of the filter's
+// namespace matching the filter.
+func (r *DocRepoFilesystem) CountDocumentations(ctx context.Context, filter domain.DocumentationFilter) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return int64(len(r.matchingEntries(filter))), nil
+}
+
+// matchingEntries returns the unordered manifest entries of the filter's
+// namespace matching the filter. The caller must hold r.mu.
+func (r *DocRepoFilesystem) matchingEntries(filter domain.DocumentationFilter) []ManifestEntry {
+	namespace := namespaceOrDefault(filter.Namespace)
+	category := strings.Trim(filter.Category, "/")
+
+	var entries []ManifestEntry
+	for _, entry := range r.entries {
+		if entry.Namespace != namespace ||
+			(len(filter.Owner) > 0 && entry.Owner != filter.Owner) ||
+			!containsAll(entry.Tags, filter.Tags) ||
+			(len(category) > 0 && entry.Category != category && !strings.HasPrefix(entry.Category, category+"/")) {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// documentations reads the documentations of the entries in their order.
+func (r *DocRepoFilesystem) documentations(entries []ManifestEntry) ([]domain.Documentation, error) {
+	docs := make([]domain.Documentation, 0, len(entries))
+	for _, entry := range entries {
+		doc, err := r.documentation(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+// documentation reads the content of the entry's documentation.
+func (r *DocRepoFilesystem) documentation(entry ManifestEntry) (domain.Documentation, error) {
+	content, err := os.ReadFile(filepath.Join(r.root, filepath.FromSlash(entry.Path)))
+	if err != nil {
+		return domain.Documentation{}, fmt.Errorf("could not read documentation: %w", err)
+	}
+
+	return domain.Documentation{
+		Namespace: entry.Namespace,
+		Name:      entry.Name,
+		Content:   content,
+		Metadata: domain.Metadata{
+			Title:       entry.Title,
+			Description: entry.Description,
+			Owner:       entry.Owner,
+			Tags:        entry.Tags,
+			Category:    entry.Category,
+		},
+		Timestamps: domain.Timestamps{
+			CreatedAt:     entry.CreatedAt,
+			UpdatedAt:     entry.UpdatedAt,
+			LastScrapedAt: entry.LastScrapedAt,
+		},
+	}, nil
+}
+
+func (r *DocRepoFilesystem) DeleteDocumentationsExcept(ctx context.Context, keys []domain.DocumentationKey) (int64, error) {
+	return r.removeExcept(keys, func(entry ManifestEntry) error {
+		return r.removeFile(entry.Path)
+	})
+}
+
+// ArchiveDocumentationsExcept moves the documentations to the .archive
+// directory, which is not part of the manifest. The time of archiving is
+// added to the file names, e.g. repo.20240102T150405.000000000Z.md, so
+// documentations archived again never replace earlier archives.
+func (r *DocRepoFilesystem) ArchiveDocumentationsExcept(ctx context.Context, keys []domain.DocumentationKey) (int64, error) {
+	now := time.Now().UTC()
+	return r.removeExcept(keys, func(entry ManifestEntry) error {
+		src := filepath.Join(r.root, filepath.FromSlash(entry.Path))
+		dst := filepath.Join(r.root, archiveDir, filepath.FromSlash(archivePath(entry.Path, now)))
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		if err := os.Rename(src, dst); err != nil {
+			return err
+		}
+
+		removeEmptyDirs(r.root, filepath.Dir(src))
+		return nil
+	})
+}
+
+func (r *DocRepoFilesystem) MarkDocumentationsStaleExcept(ctx context.Context, keys []domain.DocumentationKey) (int64, error) {
+	keep := keySet(keys)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	for key, entry := range r.entries {
+		if keep[entry.sectionKey()] || entry.Stale {
+			continue
+		}
+
+		entry.Stale = true
+		r.entries[key] = entry
+		n++
+	}
+
+	if n == 0 {
+		return 0, nil
+	}
+
+	return n, r.writeManifest()
+}
+
+// removeExcept calls remove for all documentations whose section key is not
+// in keys and drops them from the manifest.
+func (r *DocRepoFilesystem) removeExcept(keys []domain.DocumentationKey, remove func(entry ManifestEntry) error) (int64, error) {
+	keep := keySet(keys)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	var errs []error
+	for key, entry := range r.entries {
+		if keep[entry.sectionKey()] {
+			continue
+		}
+
+		if err := remove(entry); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("could not remove %s: %w", key, err))
+			continue
+		}
+
+		delete(r.entries, key)
+		n++
+	}
+
+	if n > 0 {
+		errs = append(errs, r.writeManifest())
+	}
+
+	return n, errors.Join(errs...)
+}
+
+func (r *DocRepoFilesystem) removeFile(p string) error {
+	name := filepath.Join(r.root, filepath.FromSlash(p))
+	if err := os.Remove(name); err != nil {
+		return err
+	}
+
+	removeEmptyDirs(r.root, filepath.Dir(name))
+	return nil
+}
+
+// writeManifest writes the manifest ordered by namespace and name.
+func (r *DocRepoFilesystem) writeManifest() error {
+	manifest := Manifest{Documentations: make([]ManifestEntry, 0, len(r.entries))}
+	for _, entry := range r.entries {
+		manifest.Documentations = append(manifest.Documentations, entry)
+	}
+
+	slices.SortFunc(manifest.Documentations, func(a, b ManifestEntry) int {
+		return cmp.Or(strings.Compare(a.Namespace, b.Namespace), strings.Compare(a.Name, b.Name))
+	})
+
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(filepath.Join(r.root, ManifestFile), b); err != nil {
+		return fmt.Errorf("could not write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// documentationPath returns the slash separated path of the documentation
+// relative to the root directory. Names may contain slashes to create
+// subdirectories, but must not escape the namespace directory. The namespace
+// must not collide with the manifest.
+func documentationPath(namespace, name string) (string, error) {
+	if len(namespace) == 0 || namespace[0] == '.' || strings.Contains(namespace, "/") || namespace == ManifestFile {
+		return "", fmt.Errorf("invalid namespace %q", namespace)
+	}
+
+	for _, segment := range strings.Split(name, "/") {
+		if len(segment) == 0 || segment == "." || segment == ".." {
+			return "", fmt.Errorf("invalid documentation name %q", name)
+		}
+	}
+
+	return path.Join(namespace, name+".md"), nil
+}
+
+// writeFileAtomic writes data to a temporary file in the directory of name and
+// renames it to name afterwards.
+func writeFileAtomic(name string, data []byte) error {
+	dir := filepath.Dir(name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), name)
+}
+
+// archivePath returns the slash separated path p with the time of archiving
+// added in front of its extension.
+func archivePath(p string, archivedAt time.Time) string {
+	ext := path.Ext(p)
+	return strings.TrimSuffix(p, ext) + "." + archivedAt.Format(archiveTimeFormat) + ext
+}
+
+// removeEmptyDirs removes dir and its parents up to (excluding) root as long
+// as they are empty. Paths are compared relative to root, so relative roots
+// like "." are supported.
+func removeEmptyDirs(root, dir string) {
+	root = filepath.Clean(root)
+	for dir = filepath.Clean(dir); ; dir = filepath.Dir(dir) {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil || rel == "." || !filepath.IsLocal(rel) {
+			return
+		}
+
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+	}
+}
+
+func keySet(keys []domain.DocumentationKey) map[domain.DocumentationKey]bool {
+	set := make(map[domain.DocumentationKey]bool, len(keys))
+	for _, key := range keys {
+		set[domain.DocumentationKey{Namespace: namespaceOrDefault(key.Namespace), Name: key.Name}] = true
+	}
+
+	return set
+}
+
+func containsAll(values, required []string) bool {
+	for _, v := range required {
+		if !slices.Contains(values, v) {
+			return false
+		}
+	}
+
+	return true
+}
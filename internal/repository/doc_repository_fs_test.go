@@ -0,0 +1,587 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flohansen/documenter/internal/domain"
+	"github.com/flohansen/documenter/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocRepoFilesystem(t *testing.T) {
+	newRepo := func(t *testing.T) (*repository.DocRepoFilesystem, string) {
+		root := filepath.Join(t.TempDir(), "docs")
+		repo, err := repository.NewDocRepoFilesystem(repository.FilesystemScheme + root)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return repo, root
+	}
+
+	readManifest := func(t *testing.T, root string) repository.Manifest {
+		b, err := os.ReadFile(filepath.Join(root, repository.ManifestFile))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var manifest repository.Manifest
+		if err := json.Unmarshal(b, &manifest); err != nil {
+			t.Fatal(err)
+		}
+
+		return manifest
+	}
+
+	withoutManifestTimestamps := func(manifest repository.Manifest) repository.Manifest {
+		for i := range manifest.Documentations {
+			manifest.Documentations[i].CreatedAt = time.Time{}
+			manifest.Documentations[i].UpdatedAt = time.Time{}
+			manifest.Documentations[i].LastScrapedAt = time.Time{}
+		}
+
+		return manifest
+	}
+
+	upsert := func(t *testing.T, repo *repository.DocRepoFilesystem, docs ...domain.Documentation) {
+		for _, doc := range docs {
+			if _, err := repo.UpsertDocumentation(context.Background(), doc); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	t.Run("UpsertDocumentation", func(t *testing.T) {
+		t.Run("should write documentation and manifest", func(t *testing.T) {
+			// assign
+			repo, root := newRepo(t)
+
+			// act
+			_, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{
+				Namespace: "team",
+				Name:      "org/service",
+				Content:   []byte("# Service"),
+				Metadata:  domain.Metadata{Title: "Service", Owner: "team-a", Tags: []string{"go"}},
+			})
+
+			// assert
+			assert.NoError(t, err)
+			content, err := os.ReadFile(filepath.Join(root, "team", "org", "service.md"))
+			assert.NoError(t, err)
+			assert.Equal(t, "# Service", string(content))
+			manifest := readManifest(t, root)
+			if assert.Len(t, manifest.Documentations, 1) {
+				entry := manifest.Documentations[0]
+				assert.False(t, entry.CreatedAt.IsZero())
+				assert.Equal(t, entry.CreatedAt, entry.UpdatedAt)
+				assert.Equal(t, entry.CreatedAt, entry.LastScrapedAt)
+			}
+			assert.Equal(t, repository.Manifest{Documentations: []repository.ManifestEntry{{
+				Namespace: "team",
+				Name:      "org/service",
+				Section:   "org/service",
+				Path:      "team/org/service.md",
+				Title:     "Service",
+				Owner:     "team-a",
+				Tags:      []string{"go"},
+			}}}, withoutManifestTimestamps(manifest))
+		})
+
+		t.Run("should keep update time if content is unchanged", func(t *testing.T) {
+			// assign
+			repo, root := newRepo(t)
+			upsert(t, repo, domain.Documentation{Name: "name", Content: []byte("content")})
+			before := readManifest(t, root).Documentations[0]
+
+			// act
+			_, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "name", Content: []byte("content")})
+
+			// assert
+			assert.NoError(t, err)
+			after := readManifest(t, root).Documentations[0]
+			assert.Equal(t, before.CreatedAt, after.CreatedAt)
+			assert.Equal(t, before.UpdatedAt, after.UpdatedAt)
+			assert.False(t, after.LastScrapedAt.Before(before.LastScrapedAt))
+		})
+
+		t.Run("should overwrite documentation without leaving temporary files", func(t *testing.T) {
+			// assign
+			repo, root := newRepo(t)
+			upsert(t, repo, domain.Documentation{Name: "name", Content: []byte("change me")})
+
+			// act
+			_, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "name", Content: []byte("content")})
+
+			// assert
+			assert.NoError(t, err)
+			content, err := os.ReadFile(filepath.Join(root, "default", "name.md"))
+			assert.NoError(t, err)
+			assert.Equal(t, "content", string(content))
+			entries, err := os.ReadDir(filepath.Join(root, "default"))
+			assert.NoError(t, err)
+			assert.Len(t, entries, 1)
+		})
+
+		t.Run("should reject names escaping the namespace directory", func(t *testing.T) {
+			// assign
+			repo, _ := newRepo(t)
+
+			// act
+			_, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "../other", Content: []byte("content")})
+
+			// assert
+			assert.ErrorContains(t, err, `invalid documentation name "../other"`)
+		})
+
+		t.Run("should reject namespace colliding with manifest", func(t *testing.T) {
+			// assign
+			repo, root := newRepo(t)
+			upsert(t, repo, domain.Documentation{Name: "name", Content: []byte("content")})
+
+			// act
+			_, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Namespace: repository.ManifestFile, Name: "name", Content: []byte("content")})
+
+			// assert
+			assert.EqualError(t, err, `invalid namespace "index.json"`)
+			assert.Len(t, readManifest(t, root).Documentations, 1)
+		})
+
+		t.Run("should reset stale flag", func(t *testing.T) {
+			// assign
+			repo, root := newRepo(t)
+			upsert(t, repo, domain.Documentation{Name: "name", Content: []byte("content")})
+			if _, err := repo.MarkDocumentationsStaleExcept(context.Background(), nil); err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			_, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "name", Content: []byte("content")})
+
+			// assert
+			assert.NoError(t, err)
+			assert.False(t, readManifest(t, root).Documentations[0].Stale)
+		})
+	})
+
+	t.Run("UpsertSectionDocumentations", func(t *testing.T) {
+		t.Run("should write documentations and delete vanished documentations of the section", func(t *testing.T) {
+			// assign
+			repo, root := newRepo(t)
+			section := domain.DocumentationKey{Name: "service"}
+			if _, err := repo.UpsertSectionDocumentations(context.Background(), section, []domain.Documentation{
+				{Name: "service", Content: []byte("old")},
+				{Name: "service/old", Content: []byte("old")},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			upsert(t, repo, domain.Documentation{Name: "service/repo", Content: []byte("discovered")})
+
+			// act
+			changed, err := repo.UpsertSectionDocumentations(context.Background(), section, []domain.Documentation{
+				{Name: "service", Content: []byte("readme")},
+				{Name: "service/new", Content: []byte("new")},
+			})
+
+			// assert
+			assert.NoError(t, err)
+			assert.True(t, changed)
+			var names []string
+			for _, entry := range readManifest(t, root).Documentations {
+				names = append(names, entry.Name)
+			}
+			assert.Equal(t, []string{"service", "service/new", "service/repo"}, names)
+			_, err = os.Stat(filepath.Join(root, "default", "service", "old.md"))
+			assert.ErrorIs(t, err, os.ErrNotExist)
+		})
+
+		t.Run("should report no change if documentations are unchanged", func(t *testing.T) {
+			// assign
+			repo, _ := newRepo(t)
+			section := domain.DocumentationKey{Name: "service"}
+			docs := []domain.Documentation{{Name: "service", Content: []byte("readme")}}
+			if _, err := repo.UpsertSectionDocumentations(context.Background(), section, docs); err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			changed, err := repo.UpsertSectionDocumentations(context.Background(), section, docs)
+
+			// assert
+			assert.NoError(t, err)
+			assert.False(t, changed)
+		})
+	})
+
+	t.Run("ListDocumentations", func(t *testing.T) {
+		repo, _ := newRepo(t)
+		upsert(t, repo,
+			domain.Documentation{Name: "payments", Content: []byte("payments"), Metadata: domain.Metadata{
+				Owner: "team-a", Tags: []string{"go", "api"}, Category: "platform/payments",
+			}},
+			domain.Documentation{Name: "platform", Content: []byte("platform"), Metadata: domain.Metadata{
+				Owner: "team-b", Tags: []string{"go"}, Category: "platform",
+			}},
+			domain.Documentation{Name: "website", Content: []byte("website"), Metadata: domain.Metadata{
+				Owner: "team-a", Tags: []string{"web"}, Category: "platforms",
+			}},
+			domain.Documentation{Namespace: "other", Name: "other", Content: []byte("other")},
+		)
+
+		tests := []struct {
+			name     string
+			filter   domain.DocumentationFilter
+			expected []string
+		}{
+			{name: "should list all documentations of default namespace without filter", expected: []string{"platform", "payments", "website"}},
+			{name: "should filter by namespace", filter: domain.DocumentationFilter{Namespace: "other"}, expected: []string{"other"}},
+			{name: "should filter by owner", filter: domain.DocumentationFilter{Owner: "team-a"}, expected: []string{"payments", "website"}},
+			{name: "should filter by all tags", filter: domain.DocumentationFilter{Tags: []string{"go", "api"}}, expected: []string{"payments"}},
+			{name: "should filter by category including subcategories", filter: domain.DocumentationFilter{Category: "platform"}, expected: []string{"platform", "payments"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				// act
+				result, err := repo.ListDocumentations(context.Background(), tt.filter)
+
+				// assert
+				assert.NoError(t, err)
+				var names []string
+				for _, doc := range result {
+					names = append(names, doc.Name)
+				}
+				assert.Equal(t, tt.expected, names)
+			})
+		}
+	})
+
+	t.Run("GetDocumentation", func(t *testing.T) {
+		t.Run("should return documentation by key", func(t *testing.T) {
+			// assign
+			repo, _ := newRepo(t)
+			doc := domain.Documentation{
+				Namespace: "team",
+				Name:      "name",
+				Content:   []byte("content"),
+				Metadata:  domain.Metadata{Title: "Title", Tags: []string{"go"}},
+			}
+			upsert(t, repo, doc)
+
+			// act
+			result, err := repo.GetDocumentation(context.Background(), domain.DocumentationKey{Namespace: "team", Name: "name"})
+
+			// assert
+			assert.NoError(t, err)
+			assert.False(t, result.Timestamps.CreatedAt.IsZero())
+			assert.Equal(t, []domain.Documentation{doc}, withoutTimestamps([]domain.Documentation{result}))
+		})
+
+		t.Run("should return error if documentation does not exist", func(t *testing.T) {
+			// assign
+			repo, _ := newRepo(t)
+			upsert(t, repo, domain.Documentation{Namespace: "team", Name: "name"})
+
+			// act
+			_, err := repo.GetDocumentation(context.Background(), domain.DocumentationKey{Name: "name"})
+
+			// assert
+			assert.ErrorIs(t, err, repository.ErrDocumentationNotFound)
+		})
+	})
+
+	t.Run("ListDocumentationsPage", func(t *testing.T) {
+		listPages := func(t *testing.T, repo *repository.DocRepoFilesystem, page domain.Page) [][]string {
+			var pages [][]string
+			for {
+				result, err := repo.ListDocumentationsPage(context.Background(), domain.DocumentationFilter{}, page)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var names []string
+				for _, doc := range result.Documentations {
+					names = append(names, doc.Name)
+				}
+				pages = append(pages, names)
+				if len(result.NextCursor) == 0 {
+					return pages
+				}
+				page.Cursor = result.NextCursor
+			}
+		}
+
+		t.Run("should paginate documentations by cursor", func(t *testing.T) {
+			// assign
+			repo, _ := newRepo(t)
+			upsert(t, repo,
+				domain.Documentation{Name: "e"},
+				domain.Documentation{Name: "b"},
+				domain.Documentation{Name: "d"},
+				domain.Documentation{Name: "a"},
+				domain.Documentation{Name: "c"},
+				domain.Documentation{Namespace: "team", Name: "f"},
+			)
+
+			// act
+			pages := listPages(t, repo, domain.Page{Sort: domain.SortByName, Limit: 2})
+
+			// assert
+			assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, pages)
+		})
+
+		t.Run("should paginate documentations by update time", func(t *testing.T) {
+			// assign
+			repo, root := newRepo(t)
+			upsert(t, repo,
+				domain.Documentation{Name: "a"},
+				domain.Documentation{Name: "b"},
+				domain.Documentation{Name: "c"},
+				domain.Documentation{Name: "d"},
+			)
+			manifest := readManifest(t, root)
+			updatedAt := map[string]time.Time{
+				"a": time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+				"b": time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				"c": time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+				"d": time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			}
+			for i, entry := range manifest.Documentations {
+				manifest.Documentations[i].UpdatedAt = updatedAt[entry.Name]
+			}
+			b, err := json.Marshal(manifest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(root, repository.ManifestFile), b, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			repo, err = repository.NewDocRepoFilesystem(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			pages := listPages(t, repo, domain.Page{Sort: domain.SortByUpdated, Limit: 2})
+
+			// assert
+			assert.Equal(t, [][]string{{"a", "d"}, {"c", "b"}}, pages)
+		})
+
+		t.Run("should filter documentations by namespace and tags", func(t *testing.T) {
+			// assign
+			repo, _ := newRepo(t)
+			upsert(t, repo,
+				domain.Documentation{Name: "a", Metadata: domain.Metadata{Tags: []string{"go"}}},
+				domain.Documentation{Namespace: "team", Name: "b", Metadata: domain.Metadata{Tags: []string{"go", "api"}}},
+				domain.Documentation{Namespace: "team", Name: "c", Metadata: domain.Metadata{Tags: []string{"go"}}},
+				domain.Documentation{Namespace: "team", Name: "d", Metadata: domain.Metadata{Tags: []string{"go", "api"}}},
+			)
+			filter := domain.DocumentationFilter{Namespace: "team", Tags: []string{"api", "go"}}
+
+			// act
+			result, err := repo.ListDocumentationsPage(context.Background(), filter, domain.Page{})
+
+			// assert
+			assert.NoError(t, err)
+			if assert.Len(t, result.Documentations, 2) {
+				assert.Equal(t, "b", result.Documentations[0].Name)
+				assert.Equal(t, "d", result.Documentations[1].Name)
+			}
+			assert.Empty(t, result.NextCursor)
+		})
+
+		t.Run("should return error for cursor of another sort order", func(t *testing.T) {
+			// assign
+			repo, _ := newRepo(t)
+			upsert(t, repo, domain.Documentation{Name: "a"}, domain.Documentation{Name: "b"})
+			result, err := repo.ListDocumentationsPage(context.Background(), domain.DocumentationFilter{}, domain.Page{Limit: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			_, err = repo.ListDocumentationsPage(context.Background(), domain.DocumentationFilter{}, domain.Page{
+				Sort:   domain.SortByUpdated,
+				Cursor: result.NextCursor,
+			})
+
+			// assert
+			assert.ErrorIs(t, err, repository.ErrInvalidCursor)
+		})
+	})
+
+	t.Run("CountDocumentations", func(t *testing.T) {
+		t.Run("should count documentations matching the filter", func(t *testing.T) {
+			// assign
+			repo, _ := newRepo(t)
+			upsert(t, repo,
+				domain.Documentation{Name: "a", Metadata: domain.Metadata{Owner: "team-a"}},
+				domain.Documentation{Name: "b", Metadata: domain.Metadata{Owner: "team-b"}},
+				domain.Documentation{Name: "c", Metadata: domain.Metadata{Owner: "team-a"}},
+				domain.Documentation{Namespace: "team", Name: "d", Metadata: domain.Metadata{Owner: "team-a"}},
+			)
+
+			// act
+			n, err := repo.CountDocumentations(context.Background(), domain.DocumentationFilter{Owner: "team-a"})
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, int64(2), n)
+		})
+	})
+
+	t.Run("DeleteDocumentationsExcept", func(t *testing.T) {
+		t.Run("should delete documentations not in keys", func(t *testing.T) {
+			// assign
+			repo, root := newRepo(t)
+			upsert(t, repo,
+				domain.Documentation{Name: "keep", Content: []byte("keep")},
+				domain.Documentation{Name: "org/remove", Content: []byte("remove")},
+			)
+
+			// act
+			n, err := repo.DeleteDocumentationsExcept(context.Background(), []domain.DocumentationKey{{Name: "keep"}})
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), n)
+			assert.FileExists(t, filepath.Join(root, "default", "keep.md"))
+			assert.NoDirExists(t, filepath.Join(root, "default", "org"))
+			assert.Len(t, readManifest(t, root).Documentations, 1)
+		})
+
+		t.Run("should keep all documentations of sections in keys", func(t *testing.T) {
+			// assign
+			repo, root := newRepo(t)
+			if _, err := repo.UpsertSectionDocumentations(context.Background(), domain.DocumentationKey{Name: "keep"}, []domain.Documentation{
+				{Name: "keep", Content: []byte("keep")},
+				{Name: "keep/guide.md", Content: []byte("guide")},
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			n, err := repo.DeleteDocumentationsExcept(context.Background(), []domain.DocumentationKey{{Name: "keep"}})
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, int64(0), n)
+			assert.Len(t, readManifest(t, root).Documentations, 2)
+		})
+
+		t.Run("should remove empty directories below a relative root", func(t *testing.T) {
+			// assign
+			t.Chdir(t.TempDir())
+			repo, err := repository.NewDocRepoFilesystem(repository.FilesystemScheme + ".")
+			if err != nil {
+				t.Fatal(err)
+			}
+			upsert(t, repo, domain.Documentation{Name: "org/remove", Content: []byte("remove")})
+
+			// act
+			n, err := repo.DeleteDocumentationsExcept(context.Background(), nil)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), n)
+			assert.NoDirExists(t, "default")
+			assert.FileExists(t, repository.ManifestFile)
+		})
+
+		t.Run("should prune documentations written by previous runs", func(t *testing.T) {
+			// assign
+			repo, root := newRepo(t)
+			upsert(t, repo, domain.Documentation{Name: "remove", Content: []byte("remove")})
+			reopened, err := repository.NewDocRepoFilesystem(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			n, err := reopened.DeleteDocumentationsExcept(context.Background(), nil)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), n)
+			assert.NoFileExists(t, filepath.Join(root, "default", "remove.md"))
+			assert.Empty(t, readManifest(t, root).Documentations)
+		})
+	})
+
+	t.Run("ArchiveDocumentationsExcept", func(t *testing.T) {
+		t.Run("should move documentations not in keys to archive", func(t *testing.T) {
+			// assign
+			repo, root := newRepo(t)
+			upsert(t, repo,
+				domain.Documentation{Name: "keep", Content: []byte("keep")},
+				domain.Documentation{Name: "remove", Content: []byte("remove")},
+			)
+
+			// act
+			n, err := repo.ArchiveDocumentationsExcept(context.Background(), []domain.DocumentationKey{{Name: "keep"}})
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), n)
+			assert.NoFileExists(t, filepath.Join(root, "default", "remove.md"))
+			archived, err := filepath.Glob(filepath.Join(root, ".archive", "default", "remove.*.md"))
+			assert.NoError(t, err)
+			if assert.Len(t, archived, 1) {
+				content, err := os.ReadFile(archived[0])
+				assert.NoError(t, err)
+				assert.Equal(t, "remove", string(content))
+			}
+			assert.Len(t, readManifest(t, root).Documentations, 1)
+		})
+
+		t.Run("should keep earlier archives of documentations", func(t *testing.T) {
+			// assign
+			repo, root := newRepo(t)
+			for _, content := range []string{"first", "second"} {
+				upsert(t, repo, domain.Documentation{Name: "remove", Content: []byte(content)})
+				if _, err := repo.ArchiveDocumentationsExcept(context.Background(), nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// act
+			archived, err := filepath.Glob(filepath.Join(root, ".archive", "default", "remove.*.md"))
+
+			// assert
+			assert.NoError(t, err)
+			var contents []string
+			for _, name := range archived {
+				content, err := os.ReadFile(name)
+				assert.NoError(t, err)
+				contents = append(contents, string(content))
+			}
+			assert.ElementsMatch(t, []string{"first", "second"}, contents)
+		})
+	})
+
+	t.Run("MarkDocumentationsStaleExcept", func(t *testing.T) {
+		t.Run("should mark documentations not in keys as stale", func(t *testing.T) {
+			// assign
+			repo, root := newRepo(t)
+			upsert(t, repo,
+				domain.Documentation{Name: "keep", Content: []byte("keep")},
+				domain.Documentation{Name: "remove", Content: []byte("remove")},
+			)
+
+			// act
+			n, err := repo.MarkDocumentationsStaleExcept(context.Background(), []domain.DocumentationKey{{Name: "keep"}})
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), n)
+			manifest := readManifest(t, root)
+			assert.False(t, manifest.Documentations[0].Stale)
+			assert.True(t, manifest.Documentations[1].Stale)
+		})
+	})
+}
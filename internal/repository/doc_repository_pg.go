@@ -2,24 +2,572 @@ package repository
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
 
 	"github.com/flohansen/documenter/internal/database"
 	"github.com/flohansen/documenter/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// ErrDocumentationNotFound is returned if a documentation does not exist.
+var ErrDocumentationNotFound = errors.New("documentation not found")
+
 type DocRepoPostgres struct {
-	q *database.Queries
+	db       database.DBTX
+	q        *database.Queries
+	blobs    BlobStore
+	codec    Codec
+	observer CompressionObserver
+}
+
+// txBeginner is implemented by connections which can start transactions,
+// e.g. *pgxpool.Pool and pgx.Tx.
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// CompressionObserver is notified about the size of each content before and
+// after it was encoded, e.g. to report compression ratios as metrics.
+type CompressionObserver interface {
+	ObserveCompression(codec Codec, rawSize, storedSize int)
+}
+
+type DocRepoOption func(*DocRepoPostgres)
+
+// WithBlobStore stores the contents of documentations in the blob store, so
+// the database only holds their metadata. Contents written before are still
+// read from the database.
+func WithBlobStore(blobs BlobStore) DocRepoOption {
+	return func(r *DocRepoPostgres) {
+		r.blobs = blobs
+	}
+}
+
+// WithCompression encodes the contents of written documentations using the
+// codec. Contents are decoded using the codec they were written with, so
+// changing the codec keeps existing documentations readable.
+func WithCompression(codec Codec) DocRepoOption {
+	return func(r *DocRepoPostgres) {
+		r.codec = codec
+	}
+}
+
+// WithCompressionObserver notifies the observer about the sizes of written
+// contents.
+func WithCompressionObserver(observer CompressionObserver) DocRepoOption {
+	return func(r *DocRepoPostgres) {
+		r.observer = observer
+	}
+}
+
+func NewDocRepoPostgres(db database.DBTX, opts ...DocRepoOption) *DocRepoPostgres {
+	r := &DocRepoPostgres{
+		db: db,
+		q:  database.New(db),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// UpsertDocumentation inserts or updates the documentation as the single
+// documentation of the section with the same name. The update time is only
+// advanced if the content changed. The content is encoded using the
+// configured codec. If a blob store is set, the content is written to the blob
+// store before the metadata is written to the database, and the previous
+// content is deleted afterwards. It reports whether the documentation was
+// created or its content changed.
+func (r *DocRepoPostgres) UpsertDocumentation(ctx context.Context, doc domain.Documentation) (bool, error) {
+	var previousRef string
+	if r.blobs != nil {
+		var err error
+		previousRef, err = r.q.GetContentRef(ctx, database.GetContentRefParams{
+			Namespace: namespaceOrDefault(doc.Namespace),
+			Name:      doc.Name,
+		})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return false, err
+		}
+	}
+
+	upserts, err := r.prepareUpserts(ctx, doc.Name, []domain.Documentation{doc}, previousRef)
+	if err != nil {
+		return false, err
+	}
+
+	changed, err := upsertDocumentations(ctx, r.q, upserts)
+	if err != nil {
+		return false, errors.Join(err, r.discardBlobs(ctx, upserts))
+	}
+	r.observeCompression(upserts)
+
+	if previousRef != upserts[0].params.ContentRef {
+		return changed, r.deleteUnreferencedBlob(ctx, previousRef)
+	}
+
+	return changed, nil
+}
+
+// UpsertSectionDocumentations writes all documentations of the section in a
+// single transaction and deletes the section's documentations which are not
+// part of docs, so readers never see a partially updated section. The
+// documentations must belong to the namespace of the section. The upserts are
+// sent to the database as one batch. It reports whether any documentation was
+// created, changed or deleted.
+func (r *DocRepoPostgres) UpsertSectionDocumentations(ctx context.Context, section domain.DocumentationKey, docs []domain.Documentation) (bool, error) {
+	beginner, ok := r.db.(txBeginner)
+	if !ok {
+		return false, errors.New("database connection does not support transactions")
+	}
+
+	namespace := namespaceOrDefault(section.Namespace)
+	names := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		if namespaceOrDefault(doc.Namespace) != namespace {
+			return false, fmt.Errorf("documentation %s does not belong to section %s", domain.DocumentationKey{Namespace: doc.Namespace, Name: doc.Name}, section)
+		}
+		names = append(names, doc.Name)
+	}
+
+	var previousRefs []string
+	if r.blobs != nil {
+		var err error
+		previousRefs, err = r.q.ListSectionContentRefs(ctx, database.ListSectionContentRefsParams{
+			Namespace: namespace,
+			Section:   section.Name,
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	upserts, err := r.prepareUpserts(ctx, section.Name, docs, previousRefs...)
+	if err != nil {
+		return false, err
+	}
+
+	changed, err := r.writeSection(ctx, beginner, namespace, section.Name, names, upserts)
+	if err != nil {
+		return false, errors.Join(err, r.discardBlobs(ctx, upserts))
+	}
+	r.observeCompression(upserts)
+
+	var errs []error
+	for _, ref := range previousRefs {
+		errs = append(errs, r.deleteUnreferencedBlob(ctx, ref))
+	}
+
+	return changed, errors.Join(errs...)
+}
+
+// writeSection upserts the documentations of the section and deletes the
+// section's documentations which are not in names in a single transaction.
+// It reports whether any documentation was created, changed or deleted.
+func (r *DocRepoPostgres) writeSection(ctx context.Context, beginner txBeginner, namespace, section string, names []string, upserts []preparedUpsert) (bool, error) {
+	tx, err := beginner.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	changed, err := upsertDocumentations(ctx, q, upserts)
+	if err != nil {
+		return false, err
+	}
+
+	deleted, err := q.DeleteSectionDocumentationsExcept(ctx, database.DeleteSectionDocumentationsExceptParams{
+		Namespace: namespace,
+		Section:   section,
+		Names:     names,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+
+	return changed || deleted > 0, nil
+}
+
+// upsertDocumentations sends the upserts to the database as one batch and
+// reports whether any documentation was created or its content changed.
+func upsertDocumentations(ctx context.Context, q *database.Queries, upserts []preparedUpsert) (bool, error) {
+	params := make([]database.UpsertDocumentationsParams, 0, len(upserts))
+	for _, upsert := range upserts {
+		params = append(params, upsert.params)
+	}
+
+	var changed bool
+	var batchErr error
+	q.UpsertDocumentations(ctx, params).QueryRow(func(i int, c bool, err error) {
+		// Statements after a failed one fail as well, as the transaction is
+		// aborted, so only the first error is relevant.
+		if err != nil && batchErr == nil {
+			batchErr = fmt.Errorf("could not upsert %s: %w", params[i].Name, err)
+		}
+		changed = changed || c
+	})
+
+	return changed, batchErr
+}
+
+// preparedUpsert is a documentation encoded for an upsert.
+type preparedUpsert struct {
+	params     database.UpsertDocumentationsParams
+	rawSize    int  // Size of the content before encoding
+	storedSize int  // Size of the encoded content
+	uploaded   bool // Whether the content was written to the blob store
+}
+
+// prepareUpserts encodes the documentations of the section for an upsert.
+// Contents are written to the blob store, if set, unless they are already
+// stored under one of the existing refs. If a documentation cannot be
+// prepared, the contents written so far are discarded.
+func (r *DocRepoPostgres) prepareUpserts(ctx context.Context, section string, docs []domain.Documentation, existingRefs ...string) ([]preparedUpsert, error) {
+	upserts := make([]preparedUpsert, 0, len(docs))
+	for _, doc := range docs {
+		upsert, err := r.prepareUpsert(ctx, section, doc, existingRefs)
+		if err != nil {
+			return nil, errors.Join(err, r.discardBlobs(ctx, upserts))
+		}
+		upserts = append(upserts, upsert)
+	}
+
+	return upserts, nil
+}
+
+// prepareUpsert encodes the documentation of the section using the
+// configured codec and writes the content to the blob store, if set, unless
+// it is already stored under one of the existing refs.
+func (r *DocRepoPostgres) prepareUpsert(ctx context.Context, section string, doc domain.Documentation, existingRefs []string) (preparedUpsert, error) {
+	namespace := namespaceOrDefault(doc.Namespace)
+	content, err := r.codec.Encode(doc.Content)
+	if err != nil {
+		return preparedUpsert{}, fmt.Errorf("could not encode content: %w", err)
+	}
+
+	upsert := preparedUpsert{
+		rawSize:    len(doc.Content),
+		storedSize: len(content),
+	}
+
+	var ref string
+	if r.blobs != nil {
+		ref = contentRef(namespace, doc.Name, content)
+		if !slices.Contains(existingRefs, ref) {
+			if err := r.blobs.PutBlob(ctx, ref, content); err != nil {
+				return preparedUpsert{}, fmt.Errorf("could not store content: %w", err)
+			}
+			upsert.uploaded = true
+		}
+
+		content = []byte{}
+	}
+
+	upsert.params = database.UpsertDocumentationsParams{
+		Namespace:    namespace,
+		Name:         doc.Name,
+		Section:      section,
+		Content:      content,
+		ContentRef:   ref,
+		ContentCodec: string(r.codec),
+		ContentHash:  contentHash(doc.Content),
+		Title:        doc.Metadata.Title,
+		Description:  doc.Metadata.Description,
+		Owner:        doc.Metadata.Owner,
+		Tags:         doc.Metadata.Tags,
+		Category:     doc.Metadata.Category,
+	}
+
+	return upsert, nil
+}
+
+// discardBlobs deletes the contents written to the blob store for upserts
+// which were not committed, unless they are referenced anyway.
+func (r *DocRepoPostgres) discardBlobs(ctx context.Context, upserts []preparedUpsert) error {
+	var errs []error
+	for _, upsert := range upserts {
+		if upsert.uploaded {
+			errs = append(errs, r.deleteUnreferencedBlob(ctx, upsert.params.ContentRef))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// observeCompression reports the sizes of the committed contents to the
+// observer, if set.
+func (r *DocRepoPostgres) observeCompression(upserts []preparedUpsert) {
+	if r.observer == nil {
+		return
+	}
+
+	for _, upsert := range upserts {
+		r.observer.ObserveCompression(r.codec, upsert.rawSize, upsert.storedSize)
+	}
 }
 
-func NewDocRepoPostgres(db database.DBTX) *DocRepoPostgres {
-	return &DocRepoPostgres{
-		q: database.New(db),
+// GetDocumentation returns the documentation with the key or
+// ErrDocumentationNotFound.
+func (r *DocRepoPostgres) GetDocumentation(ctx context.Context, key domain.DocumentationKey) (domain.Documentation, error) {
+	row, err := r.q.GetDocumentation(ctx, database.GetDocumentationParams{
+		Namespace: namespaceOrDefault(key.Namespace),
+		Name:      key.Name,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Documentation{}, ErrDocumentationNotFound
+	}
+	if err != nil {
+		return domain.Documentation{}, err
+	}
+
+	return r.documentation(ctx, row)
+}
+
+// ListDocumentations returns all documentations of the filter's namespace
+// matching the filter ordered by category and name.
+func (r *DocRepoPostgres) ListDocumentations(ctx context.Context, filter domain.DocumentationFilter) ([]domain.Documentation, error) {
+	rows, err := r.q.ListDocumentations(ctx, database.ListDocumentationsParams{
+		Namespace: namespaceOrDefault(filter.Namespace),
+		Owner:     filter.Owner,
+		Tags:      filter.Tags,
+		Category:  strings.Trim(filter.Category, "/"),
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	return r.documentations(ctx, rows)
+}
+
+// ListDocumentationsPage returns a page of the documentations of the
+// filter's namespace matching the filter. It returns ErrInvalidCursor if the
+// cursor of the page was not returned for the same sort order.
+func (r *DocRepoPostgres) ListDocumentationsPage(ctx context.Context, filter domain.DocumentationFilter, page domain.Page) (domain.DocumentationPage, error) {
+	cursor, err := parsePageCursor(page.Cursor, page.Sort)
+	if err != nil {
+		return domain.DocumentationPage{}, err
+	}
+
+	// One more row than requested is read to tell whether a next page exists.
+	limit := pageLimit(page)
+	var rows []database.Documentation
+	switch page.Sort {
+	case domain.SortByName:
+		rows, err = r.q.ListDocumentationsByName(ctx, database.ListDocumentationsByNameParams{
+			Namespace: namespaceOrDefault(filter.Namespace),
+			Owner:     filter.Owner,
+			Tags:      filter.Tags,
+			Category:  strings.Trim(filter.Category, "/"),
+			AfterName: cursor.Name,
+			PageSize:  int32(limit + 1),
+		})
+	case domain.SortByUpdated:
+		rows, err = r.q.ListDocumentationsByUpdated(ctx, database.ListDocumentationsByUpdatedParams{
+			Namespace:      namespaceOrDefault(filter.Namespace),
+			Owner:          filter.Owner,
+			Tags:           filter.Tags,
+			Category:       strings.Trim(filter.Category, "/"),
+			AfterUpdatedAt: pgtype.Timestamptz{Time: cursor.UpdatedAt, Valid: !cursor.UpdatedAt.IsZero()},
+			AfterName:      cursor.Name,
+			PageSize:       int32(limit + 1),
+		})
+	default:
+		return domain.DocumentationPage{}, fmt.Errorf("unknown sort order %d", page.Sort)
+	}
+	if err != nil {
+		return domain.DocumentationPage{}, err
+	}
+
+	var result domain.DocumentationPage
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[limit-1]
+		result.NextCursor = pageCursor{Sort: page.Sort, Name: last.Name, UpdatedAt: last.UpdatedAt.Time}.String()
+	}
+
+	result.Documentations, err = r.documentations(ctx, rows)
+	if err != nil {
+		return domain.DocumentationPage{}, err
+	}
+
+	return result, nil
+}
+
+// CountDocumentations returns the number of documentations of the filter's
+// namespace matching the filter.
+func (r *DocRepoPostgres) CountDocumentations(ctx context.Context, filter domain.DocumentationFilter) (int64, error) {
+	return r.q.CountDocumentations(ctx, database.CountDocumentationsParams{
+		Namespace: namespaceOrDefault(filter.Namespace),
+		Owner:     filter.Owner,
+		Tags:      filter.Tags,
+		Category:  strings.Trim(filter.Category, "/"),
+	})
+}
+
+// DeleteDocumentationsExcept deletes the documentations and their contents
+// in the blob store, unless an archived documentation still references them.
+func (r *DocRepoPostgres) DeleteDocumentationsExcept(ctx context.Context, keys []domain.DocumentationKey) (int64, error) {
+	namespaces, sections := splitKeys(keys)
+	refs, err := r.q.DeleteDocumentationsExcept(ctx, database.DeleteDocumentationsExceptParams{
+		Namespaces: namespaces,
+		Sections:   sections,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for _, ref := range refs {
+		errs = append(errs, r.deleteUnreferencedBlob(ctx, ref))
+	}
+
+	return int64(len(refs)), errors.Join(errs...)
 }
 
-func (r *DocRepoPostgres) UpsertDocumentation(ctx context.Context, doc domain.Documentation) error {
-	return r.q.UpsertDocumentation(ctx, database.UpsertDocumentationParams{
-		Name:    doc.Name,
-		Content: doc.Content,
+func (r *DocRepoPostgres) ArchiveDocumentationsExcept(ctx context.Context, keys []domain.DocumentationKey) (int64, error) {
+	namespaces, sections := splitKeys(keys)
+	return r.q.ArchiveDocumentationsExcept(ctx, database.ArchiveDocumentationsExceptParams{
+		Namespaces: namespaces,
+		Sections:   sections,
 	})
 }
+
+func (r *DocRepoPostgres) MarkDocumentationsStaleExcept(ctx context.Context, keys []domain.DocumentationKey) (int64, error) {
+	namespaces, sections := splitKeys(keys)
+	return r.q.MarkDocumentationsStaleExcept(ctx, database.MarkDocumentationsStaleExceptParams{
+		Namespaces: namespaces,
+		Sections:   sections,
+	})
+}
+
+// documentations converts the rows to documentations including their
+// contents.
+func (r *DocRepoPostgres) documentations(ctx context.Context, rows []database.Documentation) ([]domain.Documentation, error) {
+	docs := make([]domain.Documentation, 0, len(rows))
+	for _, row := range rows {
+		doc, err := r.documentation(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+func (r *DocRepoPostgres) documentation(ctx context.Context, row database.Documentation) (domain.Documentation, error) {
+	content, err := r.content(ctx, row)
+	if err != nil {
+		return domain.Documentation{}, err
+	}
+
+	return domain.Documentation{
+		Namespace: row.Namespace,
+		Name:      row.Name,
+		Content:   content,
+		Metadata: domain.Metadata{
+			Title:       row.Title,
+			Description: row.Description,
+			Owner:       row.Owner,
+			Tags:        row.Tags,
+			Category:    row.Category,
+		},
+		Timestamps: domain.Timestamps{
+			CreatedAt:     row.CreatedAt.Time,
+			UpdatedAt:     row.UpdatedAt.Time,
+			LastScrapedAt: row.LastScrapedAt.Time,
+		},
+	}, nil
+}
+
+// content returns the decoded content of the documentation, which is read
+// from the blob store if the documentation references one.
+func (r *DocRepoPostgres) content(ctx context.Context, row database.Documentation) ([]byte, error) {
+	stored := row.Content
+	if len(row.ContentRef) > 0 {
+		if r.blobs == nil {
+			return nil, fmt.Errorf("content of %s is stored in a blob store, but none is configured", row.Name)
+		}
+
+		var err error
+		stored, err = r.blobs.GetBlob(ctx, row.ContentRef)
+		if err != nil {
+			return nil, fmt.Errorf("could not read content of %s: %w", row.Name, err)
+		}
+	}
+
+	content, err := Codec(row.ContentCodec).Decode(stored)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode content of %s: %w", row.Name, err)
+	}
+
+	return content, nil
+}
+
+// deleteUnreferencedBlob deletes the blob with the given key if no
+// documentation references it anymore.
+func (r *DocRepoPostgres) deleteUnreferencedBlob(ctx context.Context, ref string) error {
+	if len(ref) == 0 || r.blobs == nil {
+		return nil
+	}
+
+	referenced, err := r.q.IsContentReferenced(ctx, ref)
+	if err != nil || referenced {
+		return err
+	}
+
+	if err := r.blobs.DeleteBlob(ctx, ref); err != nil {
+		return fmt.Errorf("could not delete content %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+// contentHash returns the hex encoded SHA-256 hash of the raw content, which
+// tells whether a content changed independent of its codec and location.
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// contentRef returns the blob key of the encoded content. Keys are unique per
+// documentation and content, so archived documentations keep their content
+// when the documentation is updated.
+func contentRef(namespace, name string, content []byte) string {
+	return path.Join(namespace, name, contentHash(content))
+}
+
+// splitKeys returns the namespaces and names of the keys as parallel slices.
+func splitKeys(keys []domain.DocumentationKey) ([]string, []string) {
+	namespaces := make([]string, 0, len(keys))
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		namespaces = append(namespaces, namespaceOrDefault(key.Namespace))
+		names = append(names, key.Name)
+	}
+
+	return namespaces, names
+}
+
+func namespaceOrDefault(namespace string) string {
+	if len(namespace) == 0 {
+		return domain.DefaultNamespace
+	}
+
+	return namespace
+}
@@ -1,116 +1,360 @@
 package repository_test
 
 import (
+	"bytes"
 	"context"
-	"errors"
+	"sync"
 	"testing"
 
-	"github.com/flohansen/documenter/internal/database"
 	"github.com/flohansen/documenter/internal/domain"
 	"github.com/flohansen/documenter/internal/repository"
 	"github.com/flohansen/documenter/test/testhelpers"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestDocRepoPostgres_Integration(t *testing.T) {
 	container := testhelpers.StartPostgresContainer(t, testhelpers.WithMigration("../../sql/migrations"))
 
-	db, err := pgx.Connect(context.Background(), container.Dsn())
+	pool, err := pgxpool.New(context.Background(), container.Dsn())
 	if err != nil {
 		t.Fatal(err)
 	}
-	if err := db.Ping(context.Background()); err != nil {
+	defer pool.Close()
+	if err := pool.Ping(context.Background()); err != nil {
 		t.Fatal(err)
 	}
 
-	getDoc := getDoc(t, db)
-	insertDoc := insertDoc(t, db)
-	beforeEach := beforeEach(t, db)
+	db := stdlib.OpenDBFromPool(pool)
+	defer db.Close()
 
-	repo := repository.NewDocRepoPostgres(db)
+	testDocRepository(t, repository.NewDocRepoPostgres(pool), db)
 
-	t.Run("UpsertDocumentation", func(t *testing.T) {
-		t.Run("should insert new documentation", func(t *testing.T) {
+	t.Run("BlobStore", func(t *testing.T) {
+		beforeEach := beforeEach(t, db)
+		getDoc := getDoc(t, db)
+
+		newRepo := func() (*repository.DocRepoPostgres, *memBlobStore) {
+			blobs := &memBlobStore{blobs: make(map[string][]byte)}
+			return repository.NewDocRepoPostgres(pool, repository.WithBlobStore(blobs)), blobs
+		}
+
+		t.Run("should store content in blob store", func(t *testing.T) {
 			beforeEach()
 
 			// assign
+			repo, blobs := newRepo()
+
 			// act
-			err := repo.UpsertDocumentation(context.Background(), domain.Documentation{
-				Name:    "name",
-				Content: []byte("content"),
-			})
+			_, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "name", Content: []byte("content")})
+
+			// assert
+			assert.NoError(t, err)
+			assert.Empty(t, getDoc("name").Content)
+			assert.Equal(t, []string{"content"}, blobs.values())
+			docs, err := repo.ListDocumentations(context.Background(), domain.DocumentationFilter{})
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("content"), docs[0].Content)
+		})
+
+		t.Run("should replace content of updated documentation", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			repo, blobs := newRepo()
+			if _, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "name", Content: []byte("change me")}); err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			_, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "name", Content: []byte("content")})
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"content"}, blobs.values())
+		})
+
+		t.Run("should delete content of deleted documentations", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			repo, blobs := newRepo()
+			if _, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "remove", Content: []byte("remove")}); err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			n, err := repo.DeleteDocumentationsExcept(context.Background(), nil)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), n)
+			assert.Empty(t, blobs.values())
+		})
+
+		t.Run("should keep content of archived documentations", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			repo, blobs := newRepo()
+			if _, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "remove", Content: []byte("remove")}); err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			n, err := repo.ArchiveDocumentationsExcept(context.Background(), nil)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), n)
+			assert.Equal(t, []string{"remove"}, blobs.values())
+		})
+	})
+
+	t.Run("Compression", func(t *testing.T) {
+		beforeEach := beforeEach(t, db)
+		getDoc := getDoc(t, db)
+		insertDoc := insertDoc(t, db)
+
+		t.Run("should store compressed content", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			observer := &sizeObserver{}
+			repo := repository.NewDocRepoPostgres(pool,
+				repository.WithCompression(repository.CodecZstd),
+				repository.WithCompressionObserver(observer))
+			content := bytes.Repeat([]byte("content "), 100)
+
+			// act
+			_, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "name", Content: content})
 
 			// assert
 			assert.NoError(t, err)
-			assert.Equal(t, database.Documentation{
-				Name:    "name",
-				Content: []byte("content"),
-			}, getDoc("name"))
+			stored := getDoc("name").Content
+			assert.Less(t, len(stored), len(content))
+			assert.Equal(t, []sizeObservation{{repository.CodecZstd, len(content), len(stored)}}, observer.observations)
+			docs, err := repo.ListDocumentations(context.Background(), domain.DocumentationFilter{})
+			assert.NoError(t, err)
+			assert.Equal(t, content, docs[0].Content)
 		})
 
-		t.Run("should update existing documentation", func(t *testing.T) {
+		t.Run("should read contents written with another codec", func(t *testing.T) {
 			beforeEach()
 
 			// assign
-			insertDoc(database.Documentation{
-				ID:      0,
-				Name:    "name",
-				Content: []byte("change me"),
+			insertDoc(storedDoc{Name: "legacy", Content: []byte("legacy")})
+			gzipRepo := repository.NewDocRepoPostgres(pool, repository.WithCompression(repository.CodecGzip))
+			if _, err := gzipRepo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "gzip", Content: []byte("gzip")}); err != nil {
+				t.Fatal(err)
+			}
+			repo := repository.NewDocRepoPostgres(pool, repository.WithCompression(repository.CodecZstd))
+
+			// act
+			docs, err := repo.ListDocumentations(context.Background(), domain.DocumentationFilter{})
+
+			// assert
+			assert.NoError(t, err)
+			if assert.Len(t, docs, 2) {
+				assert.Equal(t, []byte("gzip"), docs[0].Content)
+				assert.Equal(t, []byte("legacy"), docs[1].Content)
+			}
+		})
+
+		t.Run("should report no change for unchanged content stored without hash", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			blobs := &memBlobStore{blobs: make(map[string][]byte)}
+			repo := repository.NewDocRepoPostgres(pool,
+				repository.WithBlobStore(blobs),
+				repository.WithCompression(repository.CodecZstd))
+			if _, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "name", Content: []byte("content")}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := db.Exec("UPDATE documentations SET content_hash = ''"); err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			changed, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "name", Content: []byte("content")})
+
+			// assert
+			assert.NoError(t, err)
+			assert.False(t, changed)
+		})
+
+		t.Run("should store compressed content in blob store", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			blobs := &memBlobStore{blobs: make(map[string][]byte)}
+			repo := repository.NewDocRepoPostgres(pool,
+				repository.WithBlobStore(blobs),
+				repository.WithCompression(repository.CodecGzip))
+
+			// act
+			_, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "name", Content: []byte("content")})
+
+			// assert
+			assert.NoError(t, err)
+			if values := blobs.values(); assert.Len(t, values, 1) {
+				assert.NotEqual(t, "content", values[0])
+			}
+			docs, err := repo.ListDocumentations(context.Background(), domain.DocumentationFilter{})
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("content"), docs[0].Content)
+		})
+	})
+
+	t.Run("UpsertSectionDocumentations", func(t *testing.T) {
+		beforeEach := beforeEach(t, db)
+		getDoc := getDoc(t, db)
+		repo := repository.NewDocRepoPostgres(pool)
+		section := domain.DocumentationKey{Name: "service"}
+		listNames := func(namespace string) []string {
+			docs, err := repo.ListDocumentations(context.Background(), domain.DocumentationFilter{Namespace: namespace})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, doc := range docs {
+				names = append(names, doc.Name)
+			}
+			return names
+		}
+
+		t.Run("should roll back all documentations if one cannot be written", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			if _, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "service", Content: []byte("keep")}); err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			_, err := repo.UpsertSectionDocumentations(context.Background(), section, []domain.Documentation{
+				{Name: "service", Content: []byte("change")},
+				{Name: "service/invalid.md", Metadata: domain.Metadata{Title: "\x00"}},
 			})
 
+			// assert
+			assert.ErrorContains(t, err, "could not upsert service/invalid.md")
+			assert.Equal(t, []string{"service"}, listNames(""))
+			assert.Equal(t, []byte("keep"), getDoc("service").Content)
+		})
+
+		t.Run("should delete contents of vanished documentations in blob store", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			blobs := &memBlobStore{blobs: make(map[string][]byte)}
+			repo := repository.NewDocRepoPostgres(pool, repository.WithBlobStore(blobs))
+			if _, err := repo.UpsertSectionDocumentations(context.Background(), section, []domain.Documentation{
+				{Name: "service", Content: []byte("readme")},
+				{Name: "service/old.md", Content: []byte("old")},
+			}); err != nil {
+				t.Fatal(err)
+			}
+
 			// act
-			err := repo.UpsertDocumentation(context.Background(), domain.Documentation{
-				Name:    "name",
-				Content: []byte("content"),
+			_, err := repo.UpsertSectionDocumentations(context.Background(), section, []domain.Documentation{
+				{Name: "service", Content: []byte("readme")},
 			})
 
 			// assert
 			assert.NoError(t, err)
-			assert.Equal(t, database.Documentation{
-				ID:      0,
-				Name:    "name",
-				Content: []byte("content"),
-			}, getDoc("name"))
+			assert.Equal(t, []string{"readme"}, blobs.values())
+		})
+
+		t.Run("should discard contents and observations of rolled back documentations", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			blobs := &memBlobStore{blobs: make(map[string][]byte)}
+			if _, err := repository.NewDocRepoPostgres(pool, repository.WithBlobStore(blobs)).
+				UpsertDocumentation(context.Background(), domain.Documentation{Name: "service", Content: []byte("keep")}); err != nil {
+				t.Fatal(err)
+			}
+			observer := &sizeObserver{}
+			repo := repository.NewDocRepoPostgres(pool,
+				repository.WithBlobStore(blobs),
+				repository.WithCompressionObserver(observer))
+
+			// act
+			_, err := repo.UpsertSectionDocumentations(context.Background(), section, []domain.Documentation{
+				{Name: "service", Content: []byte("change")},
+				{Name: "service/invalid.md", Metadata: domain.Metadata{Title: "\x00"}},
+			})
+
+			// assert
+			assert.ErrorContains(t, err, "could not upsert service/invalid.md")
+			assert.Equal(t, []string{"keep"}, blobs.values())
+			assert.Empty(t, observer.observations)
 		})
 	})
 }
 
-func beforeEach(t *testing.T, db *pgx.Conn) func() {
-	return func() {
-		if _, err := db.Exec(context.Background(), "DELETE FROM documentations"); err != nil {
-			t.Fatal(err)
-		}
-	}
+type sizeObservation struct {
+	Codec      repository.Codec
+	RawSize    int
+	StoredSize int
 }
 
-func insertDoc(t *testing.T, db *pgx.Conn) func(doc database.Documentation) {
-	return func(doc database.Documentation) {
-		if _, err := db.Exec(context.Background(),
-			"INSERT INTO documentations (id, name, content) VALUES ($1, $2, $3)", doc.ID, doc.Name, doc.Content,
-		); err != nil {
-			t.Fatal(err)
-		}
+// sizeObserver records the observations of a repository.CompressionObserver.
+type sizeObserver struct {
+	observations []sizeObservation
+}
+
+func (o *sizeObserver) ObserveCompression(codec repository.Codec, rawSize, storedSize int) {
+	o.observations = append(o.observations, sizeObservation{codec, rawSize, storedSize})
+}
+
+// memBlobStore is an in-memory repository.BlobStore.
+type memBlobStore struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func (s *memBlobStore) PutBlob(ctx context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blobs[key] = data
+	return nil
+}
+
+func (s *memBlobStore) GetBlob(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, repository.ErrBlobNotFound
 	}
+
+	return data, nil
 }
 
-func getDoc(t *testing.T, db *pgx.Conn) func(name string) database.Documentation {
-	return func(name string) database.Documentation {
-		row := db.QueryRow(context.Background(),
-			"SELECT name, content FROM documentations WHERE name = $1 LIMIT 1", name)
-
-		var doc database.Documentation
-		if err := row.Scan(
-			&doc.Name,
-			&doc.Content,
-		); err != nil {
-			if errors.Is(err, pgx.ErrNoRows) {
-				return database.Documentation{}
-			}
+func (s *memBlobStore) DeleteBlob(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-			t.Fatal(err)
-		}
+	delete(s.blobs, key)
+	return nil
+}
 
-		return doc
+// values returns the contents of all blobs.
+func (s *memBlobStore) values() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var values []string
+	for _, data := range s.blobs {
+		values = append(values, string(data))
 	}
+
+	return values
 }
@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/flohansen/documenter/internal/database/sqlite"
+	"github.com/flohansen/documenter/internal/domain"
+)
+
+// DocRepoSQLite stores documentations in a SQLite database. Tags and the keys
+// passed to the Except methods are encoded as JSON arrays.
+type DocRepoSQLite struct {
+	db *sql.DB
+	q  *sqlite.Queries
+}
+
+func NewDocRepoSQLite(db *sql.DB) *DocRepoSQLite {
+	return &DocRepoSQLite{
+		db: db,
+		q:  sqlite.New(db),
+	}
+}
+
+// UpsertDocumentation inserts or updates the documentation as the single
+// documentation of the section with the same name. The update time is only
+// advanced if the content changed. It reports whether the documentation was
+// created or its content changed.
+func (r *DocRepoSQLite) UpsertDocumentation(ctx context.Context, doc domain.Documentation) (bool, error) {
+	return r.q.UpsertDocumentation(ctx, upsertParamsSQLite(doc.Name, doc, time.Now().UTC()))
+}
+
+// UpsertSectionDocumentations writes all documentations of the section in a
+// single transaction and deletes the section's documentations which are not
+// part of docs, so readers never see a partially updated section. The
+// documentations must belong to the namespace of the section. It reports
+// whether any documentation was created, changed or deleted.
+func (r *DocRepoSQLite) UpsertSectionDocumentations(ctx context.Context, section domain.DocumentationKey, docs []domain.Documentation) (bool, error) {
+	namespace := namespaceOrDefault(section.Namespace)
+	names := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		if namespaceOrDefault(doc.Namespace) != namespace {
+			return false, fmt.Errorf("documentation %s does not belong to section %s", domain.DocumentationKey{Namespace: doc.Namespace, Name: doc.Name}, section)
+		}
+		names = append(names, doc.Name)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	q := r.q.WithTx(tx)
+	now := time.Now().UTC()
+	var changed bool
+	for _, doc := range docs {
+		c, err := q.UpsertDocumentation(ctx, upsertParamsSQLite(section.Name, doc, now))
+		if err != nil {
+			return false, fmt.Errorf("could not upsert %s: %w", doc.Name, err)
+		}
+		changed = changed || c
+	}
+
+	deleted, err := q.DeleteSectionDocumentationsExcept(ctx, sqlite.DeleteSectionDocumentationsExceptParams{
+		Namespace: namespace,
+		Section:   section.Name,
+		Names:     encodeJSONArray(names),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return changed || deleted > 0, nil
+}
+
+// upsertParamsSQLite returns the parameters to upsert the documentation of
+// the section at the given time. A nil content is stored as empty content,
+// as the driver would bind it as NULL.
+func upsertParamsSQLite(section string, doc domain.Documentation, now time.Time) sqlite.UpsertDocumentationParams {
+	content := doc.Content
+	if content == nil {
+		content = []byte{}
+	}
+
+	return sqlite.UpsertDocumentationParams{
+		Namespace:   namespaceOrDefault(doc.Namespace),
+		Name:        doc.Name,
+		Section:     section,
+		Content:     content,
+		Title:       doc.Metadata.Title,
+		Description: doc.Metadata.Description,
+		Owner:       doc.Metadata.Owner,
+		Tags:        encodeJSONArray(doc.Metadata.Tags),
+		Category:    doc.Metadata.Category,
+		Now:         now,
+	}
+}
+
+// GetDocumentation returns the documentation with the key or
+// ErrDocumentationNotFound.
+func (r *DocRepoSQLite) GetDocumentation(ctx context.Context, key domain.DocumentationKey) (domain.Documentation, error) {
+	row, err := r.q.GetDocumentation(ctx, sqlite.GetDocumentationParams{
+		Namespace: namespaceOrDefault(key.Namespace),
+		Name:      key.Name,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Documentation{}, ErrDocumentationNotFound
+	}
+	if err != nil {
+		return domain.Documentation{}, err
+	}
+
+	return documentationSQLite(row)
+}
+
+// ListDocumentations returns all documentations of the filter's namespace
+// matching the filter ordered by category and name.
+func (r *DocRepoSQLite) ListDocumentations(ctx context.Context, filter domain.DocumentationFilter) ([]domain.Documentation, error) {
+	rows, err := r.q.ListDocumentations(ctx, sqlite.ListDocumentationsParams{
+		Namespace: namespaceOrDefault(filter.Namespace),
+		Owner:     filter.Owner,
+		Tags:      encodeJSONArray(filter.Tags),
+		Category:  strings.Trim(filter.Category, "/"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return documentationsSQLite(rows)
+}
+
+// ListDocumentationsPage returns a page of the documentations of the
+// filter's namespace matching the filter. It returns ErrInvalidCursor if the
+// cursor of the page was not returned for the same sort order.
+func (r *DocRepoSQLite) ListDocumentationsPage(ctx context.Context, filter domain.DocumentationFilter, page domain.Page) (domain.DocumentationPage, error) {
+	cursor, err := parsePageCursor(page.Cursor, page.Sort)
+	if err != nil {
+		return domain.DocumentationPage{}, err
+	}
+
+	// One more row than requested is read to tell whether a next page exists.
+	limit := pageLimit(page)
+	var rows []sqlite.Documentation
+	switch page.Sort {
+	case domain.SortByName:
+		rows, err = r.q.ListDocumentationsByName(ctx, sqlite.ListDocumentationsByNameParams{
+			Namespace: namespaceOrDefault(filter.Namespace),
+			Owner:     filter.Owner,
+			Tags:      encodeJSONArray(filter.Tags),
+			Category:  strings.Trim(filter.Category, "/"),
+			AfterName: cursor.Name,
+			PageSize:  int64(limit + 1),
+		})
+	case domain.SortByUpdated:
+		rows, err = r.q.ListDocumentationsByUpdated(ctx, sqlite.ListDocumentationsByUpdatedParams{
+			Namespace:      namespaceOrDefault(filter.Namespace),
+			Owner:          filter.Owner,
+			Tags:           encodeJSONArray(filter.Tags),
+			Category:       strings.Trim(filter.Category, "/"),
+			AfterUpdatedAt: sql.NullTime{Time: cursor.UpdatedAt.UTC(), Valid: !cursor.UpdatedAt.IsZero()},
+			AfterName:      cursor.Name,
+			PageSize:       int64(limit + 1),
+		})
+	default:
+		return domain.DocumentationPage{}, fmt.Errorf("unknown sort order %d", page.Sort)
+	}
+	if err != nil {
+		return domain.DocumentationPage{}, err
+	}
+
+	var result domain.DocumentationPage
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[limit-1]
+		result.NextCursor = pageCursor{Sort: page.Sort, Name: last.Name, UpdatedAt: last.UpdatedAt}.String()
+	}
+
+	result.Documentations, err = documentationsSQLite(rows)
+	if err != nil {
+		return domain.DocumentationPage{}, err
+	}
+
+	return result, nil
+}
+
+// CountDocumentations returns the number of documentations of the filter's
+// namespace matching the filter.
+func (r *DocRepoSQLite) CountDocumentations(ctx context.Context, filter domain.DocumentationFilter) (int64, error) {
+	return r.q.CountDocumentations(ctx, sqlite.CountDocumentationsParams{
+		Namespace: namespaceOrDefault(filter.Namespace),
+		Owner:     filter.Owner,
+		Tags:      encodeJSONArray(filter.Tags),
+		Category:  strings.Trim(filter.Category, "/"),
+	})
+}
+
+func (r *DocRepoSQLite) DeleteDocumentationsExcept(ctx context.Context, keys []domain.DocumentationKey) (int64, error) {
+	namespaces, sections := encodeKeys(keys)
+
+	return r.q.DeleteDocumentationsExcept(ctx, sqlite.DeleteDocumentationsExceptParams{
+		Namespaces: namespaces,
+		Sections:   sections,
+	})
+}
+
+// ArchiveDocumentationsExcept copies the documentations to the archive and
+// deletes them in a single transaction, as SQLite does not support data
+// modifying statements in common table expressions.
+func (r *DocRepoSQLite) ArchiveDocumentationsExcept(ctx context.Context, keys []domain.DocumentationKey) (int64, error) {
+	namespaces, sections := encodeKeys(keys)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	q := r.q.WithTx(tx)
+	n, err := q.CopyDocumentationsToArchiveExcept(ctx, sqlite.CopyDocumentationsToArchiveExceptParams{
+		Namespaces: namespaces,
+		Sections:   sections,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := q.DeleteDocumentationsExcept(ctx, sqlite.DeleteDocumentationsExceptParams{
+		Namespaces: namespaces,
+		Sections:   sections,
+	}); err != nil {
+		return 0, err
+	}
+
+	return n, tx.Commit()
+}
+
+func (r *DocRepoSQLite) MarkDocumentationsStaleExcept(ctx context.Context, keys []domain.DocumentationKey) (int64, error) {
+	namespaces, sections := encodeKeys(keys)
+
+	return r.q.MarkDocumentationsStaleExcept(ctx, sqlite.MarkDocumentationsStaleExceptParams{
+		Namespaces: namespaces,
+		Sections:   sections,
+	})
+}
+
+// documentationsSQLite converts the rows to documentations.
+func documentationsSQLite(rows []sqlite.Documentation) ([]domain.Documentation, error) {
+	docs := make([]domain.Documentation, 0, len(rows))
+	for _, row := range rows {
+		doc, err := documentationSQLite(row)
+		if err != nil {
+			return nil, err
+		}
+
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+// documentationSQLite converts the row to a documentation, decoding its
+// tags.
+func documentationSQLite(row sqlite.Documentation) (domain.Documentation, error) {
+	var tags []string
+	if err := json.Unmarshal([]byte(row.Tags), &tags); err != nil {
+		return domain.Documentation{}, fmt.Errorf("could not decode tags of %s: %w", row.Name, err)
+	}
+
+	return domain.Documentation{
+		Namespace: row.Namespace,
+		Name:      row.Name,
+		Content:   row.Content,
+		Metadata: domain.Metadata{
+			Title:       row.Title,
+			Description: row.Description,
+			Owner:       row.Owner,
+			Tags:        tags,
+			Category:    row.Category,
+		},
+		Timestamps: domain.Timestamps{
+			CreatedAt:     row.CreatedAt,
+			UpdatedAt:     row.UpdatedAt,
+			LastScrapedAt: row.LastScrapedAt,
+		},
+	}, nil
+}
+
+// encodeKeys returns the namespaces and names of the keys as parallel JSON
+// arrays.
+func encodeKeys(keys []domain.DocumentationKey) (string, string) {
+	namespaces, names := splitKeys(keys)
+	return encodeJSONArray(namespaces), encodeJSONArray(names)
+}
+
+// encodeJSONArray encodes the values as JSON array, encoding nil as empty
+// array.
+func encodeJSONArray(values []string) string {
+	if values == nil {
+		values = []string{}
+	}
+
+	// Marshaling a slice of strings cannot fail.
+	b, _ := json.Marshal(values)
+	return string(b)
+}
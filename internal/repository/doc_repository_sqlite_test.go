@@ -0,0 +1,29 @@
+package repository_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/flohansen/documenter/internal/repository"
+)
+
+func TestDocRepoSQLite(t *testing.T) {
+	dsn := repository.SQLiteScheme + filepath.Join(t.TempDir(), "documenter.db")
+
+	m, err := repository.NewMigratorSQLite(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := repository.OpenSQLite(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	testDocRepository(t, repository.NewDocRepoSQLite(db), db)
+}
@@ -0,0 +1,733 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flohansen/documenter/internal/app"
+	"github.com/flohansen/documenter/internal/domain"
+	"github.com/flohansen/documenter/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// docRepository is implemented by the documentation repositories of all
+// database backends.
+type docRepository interface {
+	app.DocumentationRepository
+	repository.DocumentationReader
+}
+
+// storedDoc is a row of the documentations (or archive) table used to prepare
+// and inspect the database independent of the backend.
+type storedDoc struct {
+	ID      int
+	Name    string
+	Content []byte
+	Stale   bool
+}
+
+// testDocRepository runs the repository test suite shared by all database
+// backends. The db must be connected to the same migrated database as repo.
+func testDocRepository(t *testing.T, repo docRepository, db *sql.DB) {
+	getDoc := getDoc(t, db)
+	getArchivedDocs := getArchivedDocs(t, db)
+	insertDoc := insertDoc(t, db)
+	beforeEach := beforeEach(t, db)
+
+	t.Run("UpsertDocumentation", func(t *testing.T) {
+		t.Run("should insert new documentation", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			// act
+			_, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{
+				Name:    "name",
+				Content: []byte("content"),
+			})
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, storedDoc{
+				Name:    "name",
+				Content: []byte("content"),
+			}, getDoc("name"))
+		})
+
+		t.Run("should update existing documentation", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			insertDoc(storedDoc{
+				ID:      0,
+				Name:    "name",
+				Content: []byte("change me"),
+			})
+
+			// act
+			_, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{
+				Name:    "name",
+				Content: []byte("content"),
+			})
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, storedDoc{
+				ID:      0,
+				Name:    "name",
+				Content: []byte("content"),
+			}, getDoc("name"))
+		})
+
+		t.Run("should reset stale flag", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			insertDoc(storedDoc{
+				ID:      0,
+				Name:    "name",
+				Content: []byte("content"),
+				Stale:   true,
+			})
+
+			// act
+			_, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{
+				Name:    "name",
+				Content: []byte("content"),
+			})
+
+			// assert
+			assert.NoError(t, err)
+			assert.False(t, getDoc("name").Stale)
+		})
+	})
+
+	t.Run("UpsertSectionDocumentations", func(t *testing.T) {
+		section := domain.DocumentationKey{Name: "service"}
+		listNames := func(namespace string) []string {
+			docs, err := repo.ListDocumentations(context.Background(), domain.DocumentationFilter{Namespace: namespace})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, doc := range docs {
+				names = append(names, doc.Name)
+			}
+			return names
+		}
+
+		t.Run("should write documentations and delete vanished documentations of the section", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			if _, err := repo.UpsertSectionDocumentations(context.Background(), section, []domain.Documentation{
+				{Name: "service", Content: []byte("old")},
+				{Name: "service/docs/old.md", Content: []byte("old")},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			for _, doc := range []domain.Documentation{
+				{Name: "service/repo", Content: []byte("discovered")},
+				{Namespace: "team", Name: "service/docs/old.md", Content: []byte("team")},
+			} {
+				if _, err := repo.UpsertDocumentation(context.Background(), doc); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// act
+			changed, err := repo.UpsertSectionDocumentations(context.Background(), section, []domain.Documentation{
+				{Name: "service", Content: []byte("readme")},
+				{Name: "service/docs/new.md", Content: []byte("new")},
+			})
+
+			// assert
+			assert.NoError(t, err)
+			assert.True(t, changed)
+			assert.Equal(t, []string{"service", "service/docs/new.md", "service/repo"}, listNames(""))
+			assert.Equal(t, []string{"service/docs/old.md"}, listNames("team"))
+			assert.Equal(t, []byte("readme"), getDoc("service").Content)
+		})
+
+		t.Run("should report no change if documentations are unchanged", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			docs := []domain.Documentation{{Name: "service", Content: []byte("readme")}}
+			if _, err := repo.UpsertSectionDocumentations(context.Background(), section, docs); err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			changed, err := repo.UpsertSectionDocumentations(context.Background(), section, docs)
+
+			// assert
+			assert.NoError(t, err)
+			assert.False(t, changed)
+		})
+
+		t.Run("should return error for documentations of other namespaces", func(t *testing.T) {
+			beforeEach()
+
+			// act
+			_, err := repo.UpsertSectionDocumentations(context.Background(), section, []domain.Documentation{
+				{Namespace: "team", Name: "service", Content: []byte("readme")},
+			})
+
+			// assert
+			assert.EqualError(t, err, "documentation team/service does not belong to section service")
+			assert.Empty(t, listNames("team"))
+		})
+	})
+
+	t.Run("ListDocumentations", func(t *testing.T) {
+		beforeEach()
+
+		docs := []domain.Documentation{
+			{Name: "payments", Content: []byte("payments"), Metadata: domain.Metadata{
+				Title: "Payments", Owner: "team-a", Tags: []string{"go", "api"}, Category: "platform/payments",
+			}},
+			{Name: "platform", Content: []byte("platform"), Metadata: domain.Metadata{
+				Title: "Platform", Description: "Overview", Owner: "team-b", Tags: []string{"go"}, Category: "platform",
+			}},
+			{Name: "website", Content: []byte("website"), Metadata: domain.Metadata{
+				Owner: "team-a", Tags: []string{"web"}, Category: "platforms",
+			}},
+		}
+		for _, doc := range docs {
+			if _, err := repo.UpsertDocumentation(context.Background(), doc); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		tests := []struct {
+			name     string
+			filter   domain.DocumentationFilter
+			expected []string
+		}{
+			{name: "should list all documentations without filter", expected: []string{"platform", "payments", "website"}},
+			{name: "should filter by owner", filter: domain.DocumentationFilter{Owner: "team-a"}, expected: []string{"payments", "website"}},
+			{name: "should filter by all tags", filter: domain.DocumentationFilter{Tags: []string{"go", "api"}}, expected: []string{"payments"}},
+			{name: "should filter by category including subcategories", filter: domain.DocumentationFilter{Category: "platform"}, expected: []string{"platform", "payments"}},
+			{name: "should filter by subcategory", filter: domain.DocumentationFilter{Category: "platform/payments/"}, expected: []string{"payments"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				// act
+				result, err := repo.ListDocumentations(context.Background(), tt.filter)
+
+				// assert
+				assert.NoError(t, err)
+				var names []string
+				for _, doc := range result {
+					names = append(names, doc.Name)
+				}
+				assert.Equal(t, tt.expected, names)
+			})
+		}
+
+		t.Run("should return metadata", func(t *testing.T) {
+			// assign
+			expected := docs[1]
+			expected.Namespace = domain.DefaultNamespace
+
+			// act
+			result, err := repo.ListDocumentations(context.Background(), domain.DocumentationFilter{Owner: "team-b"})
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, []domain.Documentation{expected}, withoutTimestamps(result))
+		})
+	})
+
+	t.Run("Namespaces", func(t *testing.T) {
+		t.Run("should store equal names in different namespaces", func(t *testing.T) {
+			beforeEach()
+
+			// act
+			_, errA := repo.UpsertDocumentation(context.Background(), domain.Documentation{Namespace: "a", Name: "name", Content: []byte("a")})
+			_, errB := repo.UpsertDocumentation(context.Background(), domain.Documentation{Namespace: "b", Name: "name", Content: []byte("b")})
+
+			// assert
+			assert.NoError(t, errA)
+			assert.NoError(t, errB)
+			docsA, err := repo.ListDocumentations(context.Background(), domain.DocumentationFilter{Namespace: "a"})
+			assert.NoError(t, err)
+			assert.Equal(t, []domain.Documentation{{Namespace: "a", Name: "name", Content: []byte("a"), Metadata: domain.Metadata{Tags: []string{}}}}, withoutTimestamps(docsA))
+			docsDefault, err := repo.ListDocumentations(context.Background(), domain.DocumentationFilter{})
+			assert.NoError(t, err)
+			assert.Empty(t, docsDefault)
+		})
+
+		t.Run("should prune documentations of other namespaces", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			for _, doc := range []domain.Documentation{
+				{Name: "keep", Content: []byte("keep")},
+				{Namespace: "team", Name: "keep", Content: []byte("remove")},
+			} {
+				if _, err := repo.UpsertDocumentation(context.Background(), doc); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// act
+			n, err := repo.DeleteDocumentationsExcept(context.Background(), []domain.DocumentationKey{{Namespace: domain.DefaultNamespace, Name: "keep"}})
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), n)
+			assert.Equal(t, "keep", getDoc("keep").Name)
+			docs, err := repo.ListDocumentations(context.Background(), domain.DocumentationFilter{Namespace: "team"})
+			assert.NoError(t, err)
+			assert.Empty(t, docs)
+		})
+	})
+
+	t.Run("Timestamps", func(t *testing.T) {
+		past := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		backdate := func() {
+			if _, err := db.Exec("UPDATE documentations SET created_at = $1, updated_at = $1, last_scraped_at = $1", past); err != nil {
+				t.Fatal(err)
+			}
+		}
+		listDoc := func() domain.Documentation {
+			docs, err := repo.ListDocumentations(context.Background(), domain.DocumentationFilter{})
+			if err != nil || len(docs) != 1 {
+				t.Fatalf("expected one documentation, got %d (%v)", len(docs), err)
+			}
+			return docs[0]
+		}
+
+		t.Run("should set timestamps of new documentation", func(t *testing.T) {
+			beforeEach()
+
+			// act
+			changed, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "name", Content: []byte("content")})
+
+			// assert
+			assert.NoError(t, err)
+			assert.True(t, changed)
+			ts := listDoc().Timestamps
+			assert.False(t, ts.CreatedAt.IsZero())
+			assert.WithinDuration(t, ts.CreatedAt, ts.UpdatedAt, 0)
+			assert.WithinDuration(t, ts.CreatedAt, ts.LastScrapedAt, 0)
+		})
+
+		t.Run("should keep update time and report no change if content is unchanged", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			if _, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "name", Content: []byte("content")}); err != nil {
+				t.Fatal(err)
+			}
+			backdate()
+
+			// act
+			changed, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{
+				Name:     "name",
+				Content:  []byte("content"),
+				Metadata: domain.Metadata{Title: "Title"},
+			})
+
+			// assert
+			assert.NoError(t, err)
+			assert.False(t, changed)
+			ts := listDoc().Timestamps
+			assert.WithinDuration(t, past, ts.CreatedAt, 0)
+			assert.WithinDuration(t, past, ts.UpdatedAt, 0)
+			assert.True(t, ts.LastScrapedAt.After(past))
+		})
+
+		t.Run("should advance update time if content changed", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			if _, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "name", Content: []byte("change me")}); err != nil {
+				t.Fatal(err)
+			}
+			backdate()
+
+			// act
+			changed, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Name: "name", Content: []byte("content")})
+
+			// assert
+			assert.NoError(t, err)
+			assert.True(t, changed)
+			ts := listDoc().Timestamps
+			assert.WithinDuration(t, past, ts.CreatedAt, 0)
+			assert.True(t, ts.UpdatedAt.After(past))
+			assert.WithinDuration(t, ts.UpdatedAt, ts.LastScrapedAt, 0)
+		})
+	})
+
+	t.Run("GetDocumentation", func(t *testing.T) {
+		t.Run("should return documentation by key", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			doc := domain.Documentation{
+				Namespace: "team",
+				Name:      "name",
+				Content:   []byte("content"),
+				Metadata:  domain.Metadata{Title: "Title", Tags: []string{"go"}},
+			}
+			if _, err := repo.UpsertDocumentation(context.Background(), doc); err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			result, err := repo.GetDocumentation(context.Background(), domain.DocumentationKey{Namespace: "team", Name: "name"})
+
+			// assert
+			assert.NoError(t, err)
+			assert.False(t, result.Timestamps.CreatedAt.IsZero())
+			assert.Equal(t, []domain.Documentation{doc}, withoutTimestamps([]domain.Documentation{result}))
+		})
+
+		t.Run("should return error if documentation does not exist", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			if _, err := repo.UpsertDocumentation(context.Background(), domain.Documentation{Namespace: "team", Name: "name"}); err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			_, err := repo.GetDocumentation(context.Background(), domain.DocumentationKey{Name: "name"})
+
+			// assert
+			assert.ErrorIs(t, err, repository.ErrDocumentationNotFound)
+		})
+	})
+
+	t.Run("ListDocumentationsPage", func(t *testing.T) {
+		upsertDocs := func(docs ...domain.Documentation) {
+			for _, doc := range docs {
+				if _, err := repo.UpsertDocumentation(context.Background(), doc); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		names := func(docs []domain.Documentation) []string {
+			var names []string
+			for _, doc := range docs {
+				names = append(names, doc.Name)
+			}
+			return names
+		}
+
+		t.Run("should paginate documentations by cursor", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			upsertDocs(
+				domain.Documentation{Name: "e"},
+				domain.Documentation{Name: "b"},
+				domain.Documentation{Name: "d"},
+				domain.Documentation{Name: "a"},
+				domain.Documentation{Name: "c"},
+			)
+
+			// act
+			var pages [][]string
+			page := domain.Page{Sort: domain.SortByName, Limit: 2}
+			for {
+				result, err := repo.ListDocumentationsPage(context.Background(), domain.DocumentationFilter{}, page)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				pages = append(pages, names(result.Documentations))
+				if len(result.NextCursor) == 0 {
+					break
+				}
+				page.Cursor = result.NextCursor
+			}
+
+			// assert
+			assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, pages)
+		})
+
+		t.Run("should paginate documentations by update time", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			upsertDocs(
+				domain.Documentation{Name: "a"},
+				domain.Documentation{Name: "b"},
+				domain.Documentation{Name: "c"},
+				domain.Documentation{Name: "d"},
+			)
+			for name, updatedAt := range map[string]time.Time{
+				"a": time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+				"b": time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				"c": time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+				"d": time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			} {
+				if _, err := db.Exec("UPDATE documentations SET updated_at = $1 WHERE name = $2", updatedAt, name); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// act
+			var pages [][]string
+			page := domain.Page{Sort: domain.SortByUpdated, Limit: 2}
+			for {
+				result, err := repo.ListDocumentationsPage(context.Background(), domain.DocumentationFilter{}, page)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				pages = append(pages, names(result.Documentations))
+				if len(result.NextCursor) == 0 {
+					break
+				}
+				page.Cursor = result.NextCursor
+			}
+
+			// assert
+			assert.Equal(t, [][]string{{"a", "d"}, {"c", "b"}}, pages)
+		})
+
+		t.Run("should return error for cursor of another sort order", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			upsertDocs(domain.Documentation{Name: "a"}, domain.Documentation{Name: "b"})
+			result, err := repo.ListDocumentationsPage(context.Background(), domain.DocumentationFilter{}, domain.Page{Limit: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			_, err = repo.ListDocumentationsPage(context.Background(), domain.DocumentationFilter{}, domain.Page{
+				Sort:   domain.SortByUpdated,
+				Cursor: result.NextCursor,
+			})
+
+			// assert
+			assert.ErrorIs(t, err, repository.ErrInvalidCursor)
+		})
+
+		t.Run("should filter documentations by namespace and tags", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			upsertDocs(
+				domain.Documentation{Name: "a", Metadata: domain.Metadata{Tags: []string{"go"}}},
+				domain.Documentation{Namespace: "team", Name: "b", Metadata: domain.Metadata{Tags: []string{"go", "api"}}},
+				domain.Documentation{Namespace: "team", Name: "c", Metadata: domain.Metadata{Tags: []string{"go"}}},
+				domain.Documentation{Namespace: "team", Name: "d", Metadata: domain.Metadata{Tags: []string{"go", "api"}}},
+			)
+			filter := domain.DocumentationFilter{Namespace: "team", Tags: []string{"api", "go"}}
+
+			// act
+			result, err := repo.ListDocumentationsPage(context.Background(), filter, domain.Page{})
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"b", "d"}, names(result.Documentations))
+			assert.Empty(t, result.NextCursor)
+		})
+
+		t.Run("should return error for invalid cursor", func(t *testing.T) {
+			beforeEach()
+
+			// act
+			_, err := repo.ListDocumentationsPage(context.Background(), domain.DocumentationFilter{}, domain.Page{Cursor: "invalid"})
+
+			// assert
+			assert.ErrorIs(t, err, repository.ErrInvalidCursor)
+		})
+	})
+
+	t.Run("CountDocumentations", func(t *testing.T) {
+
+		t.Run("should count documentations matching the filter", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			for _, doc := range []domain.Documentation{
+				{Name: "a", Metadata: domain.Metadata{Owner: "team-a"}},
+				{Name: "b", Metadata: domain.Metadata{Owner: "team-b"}},
+				{Name: "c", Metadata: domain.Metadata{Owner: "team-a"}},
+				{Namespace: "team", Name: "d", Metadata: domain.Metadata{Owner: "team-a"}},
+			} {
+				if _, err := repo.UpsertDocumentation(context.Background(), doc); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// act
+			n, err := repo.CountDocumentations(context.Background(), domain.DocumentationFilter{Owner: "team-a"})
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, int64(2), n)
+		})
+	})
+
+	// upsertSections writes the documentations "keep", "keep/guide.md" of the
+	// section "keep" and "remove" of the section "remove".
+	upsertSections := func() {
+		for section, docs := range map[string][]domain.Documentation{
+			"keep": {
+				{Name: "keep", Content: []byte("keep")},
+				{Name: "keep/guide.md", Content: []byte("guide")},
+			},
+			"remove": {
+				{Name: "remove", Content: []byte("remove")},
+			},
+		} {
+			if _, err := repo.UpsertSectionDocumentations(context.Background(), domain.DocumentationKey{Name: section}, docs); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	t.Run("DeleteDocumentationsExcept", func(t *testing.T) {
+		t.Run("should delete documentations of sections not in keys", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			upsertSections()
+
+			// act
+			n, err := repo.DeleteDocumentationsExcept(context.Background(), []domain.DocumentationKey{{Name: "keep"}})
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), n)
+			assert.Equal(t, "keep", getDoc("keep").Name)
+			assert.Equal(t, "keep/guide.md", getDoc("keep/guide.md").Name)
+			assert.Equal(t, storedDoc{}, getDoc("remove"))
+		})
+	})
+
+	t.Run("ArchiveDocumentationsExcept", func(t *testing.T) {
+		t.Run("should move documentations of sections not in keys to archive", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			upsertSections()
+
+			// act
+			n, err := repo.ArchiveDocumentationsExcept(context.Background(), []domain.DocumentationKey{{Name: "keep"}})
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), n)
+			assert.Equal(t, "keep", getDoc("keep").Name)
+			assert.Equal(t, "keep/guide.md", getDoc("keep/guide.md").Name)
+			assert.Equal(t, storedDoc{}, getDoc("remove"))
+			assert.Equal(t, []storedDoc{
+				{Name: "remove", Content: []byte("remove")},
+			}, getArchivedDocs())
+		})
+	})
+
+	t.Run("MarkDocumentationsStaleExcept", func(t *testing.T) {
+		t.Run("should mark documentations of sections not in keys as stale", func(t *testing.T) {
+			beforeEach()
+
+			// assign
+			upsertSections()
+
+			// act
+			n, err := repo.MarkDocumentationsStaleExcept(context.Background(), []domain.DocumentationKey{{Name: "keep"}})
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), n)
+			assert.False(t, getDoc("keep").Stale)
+			assert.False(t, getDoc("keep/guide.md").Stale)
+			assert.True(t, getDoc("remove").Stale)
+		})
+	})
+}
+
+// withoutTimestamps returns the documentations with zero timestamps, so they
+// can be compared independent of the time they were written.
+func withoutTimestamps(docs []domain.Documentation) []domain.Documentation {
+	result := make([]domain.Documentation, 0, len(docs))
+	for _, doc := range docs {
+		doc.Timestamps = domain.Timestamps{}
+		result = append(result, doc)
+	}
+
+	return result
+}
+
+func beforeEach(t *testing.T, db *sql.DB) func() {
+	return func() {
+		if _, err := db.Exec("DELETE FROM documentations"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec("DELETE FROM documentations_archive"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func insertDoc(t *testing.T, db *sql.DB) func(doc storedDoc) {
+	return func(doc storedDoc) {
+		if _, err := db.Exec(
+			"INSERT INTO documentations (id, name, content, stale) VALUES ($1, $2, $3, $4)", doc.ID, doc.Name, doc.Content, doc.Stale,
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func getDoc(t *testing.T, db *sql.DB) func(name string) storedDoc {
+	return func(name string) storedDoc {
+		row := db.QueryRow(
+			"SELECT name, content, stale FROM documentations WHERE name = $1 LIMIT 1", name)
+
+		var doc storedDoc
+		if err := row.Scan(
+			&doc.Name,
+			&doc.Content,
+			&doc.Stale,
+		); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return storedDoc{}
+			}
+
+			t.Fatal(err)
+		}
+
+		return doc
+	}
+}
+
+func getArchivedDocs(t *testing.T, db *sql.DB) func() []storedDoc {
+	return func() []storedDoc {
+		rows, err := db.Query("SELECT name, content FROM documentations_archive ORDER BY id")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+
+		var docs []storedDoc
+		for rows.Next() {
+			var doc storedDoc
+			if err := rows.Scan(
+				&doc.Name,
+				&doc.Content,
+			); err != nil {
+				t.Fatal(err)
+			}
+
+			docs = append(docs, doc)
+		}
+
+		return docs
+	}
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// MigrationStatus describes the state of the database schema.
+type MigrationStatus struct {
+	Version uint   // Version of the last applied migration (0 if none)
+	Dirty   bool   // Whether the last migration failed and needs manual fixing
+	Latest  uint   // Version of the latest available migration
+	Pending []uint // Versions of the migrations not applied yet
+}
+
+// Migrator applies the embedded migrations of a database backend, see
+// NewMigratorPostgres and NewMigratorSQLite.
+type Migrator struct {
+	migrate  *migrate.Migrate
+	versions []uint
+}
+
+// newMigrator creates a migrator applying the migrations of fsys using the
+// database driver. The driver is closed if the migrator cannot be created.
+func newMigrator(fsys fs.FS, driverName string, driver database.Driver) (*Migrator, error) {
+	src, err := iofs.New(fsys, ".")
+	if err != nil {
+		driver.Close()
+		return nil, fmt.Errorf("could not read migrations: %w", err)
+	}
+
+	versions, err := sourceVersions(src)
+	if err != nil {
+		src.Close()
+		driver.Close()
+		return nil, fmt.Errorf("could not read migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, driverName, driver)
+	if err != nil {
+		src.Close()
+		driver.Close()
+		return nil, fmt.Errorf("could not create migrate instance: %w", err)
+	}
+
+	return &Migrator{
+		migrate:  m,
+		versions: versions,
+	}, nil
+}
+
+// Up applies all pending migrations. If another migrator is applying
+// migrations at the same time, Up waits for it to finish first.
+func (m *Migrator) Up() error {
+	if err := m.migrate.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("migrate up error: %w", err)
+	}
+
+	return nil
+}
+
+// Down reverts the given number of applied migrations.
+func (m *Migrator) Down(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("steps must be positive, got %d", steps)
+	}
+
+	if err := m.migrate.Steps(-steps); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("migrate down error: less than %d migration(s) applied", steps)
+		}
+
+		return fmt.Errorf("migrate down error: %w", err)
+	}
+
+	return nil
+}
+
+// Status returns the current state of the database schema.
+func (m *Migrator) Status() (MigrationStatus, error) {
+	version, dirty, err := m.migrate.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return MigrationStatus{}, fmt.Errorf("could not read schema version: %w", err)
+	}
+
+	status := MigrationStatus{Version: version, Dirty: dirty}
+	for _, v := range m.versions {
+		status.Latest = v
+		if v > version {
+			status.Pending = append(status.Pending, v)
+		}
+	}
+
+	return status, nil
+}
+
+// Close closes the database connection.
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.migrate.Close()
+	return errors.Join(srcErr, dbErr)
+}
+
+// latestVersion returns the version of the latest migration of fsys.
+func latestVersion(fsys fs.FS) (uint, error) {
+	src, err := iofs.New(fsys, ".")
+	if err != nil {
+		return 0, fmt.Errorf("could not read migrations: %w", err)
+	}
+	defer src.Close()
+
+	versions, err := sourceVersions(src)
+	if err != nil {
+		return 0, fmt.Errorf("could not read migrations: %w", err)
+	}
+	if len(versions) == 0 {
+		return 0, nil
+	}
+
+	return versions[len(versions)-1], nil
+}
+
+// sourceVersions returns the versions of all migrations in ascending order.
+func sourceVersions(src source.Driver) ([]uint, error) {
+	version, err := src.First()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	versions := []uint{version}
+	for {
+		version, err = src.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return versions, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, version)
+	}
+}
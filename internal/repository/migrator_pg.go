@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/flohansen/documenter/sql/migrations"
+	pgxmigrate "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// NewMigratorPostgres connects to the PostgreSQL database using the
+// connection string and pool options, see ParsePoolConfig. Migrations hold a
+// PostgreSQL advisory lock while running, so concurrent importer replicas wait
+// for each other instead of applying the same migration twice.
+func NewMigratorPostgres(dsn string, opts ...PoolOption) (*Migrator, error) {
+	cfg, err := ParsePoolConfig(dsn, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	db := stdlib.OpenDB(*cfg.ConnConfig)
+	driver, err := pgxmigrate.WithInstance(db, &pgxmigrate.Config{})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not connect to database: %w", err)
+	}
+
+	return newMigrator(migrations.FS, "pgx5", driver)
+}
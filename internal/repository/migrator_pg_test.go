@@ -0,0 +1,122 @@
+package repository_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/flohansen/documenter/internal/repository"
+	"github.com/flohansen/documenter/test/testhelpers"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigratorPostgres_Integration(t *testing.T) {
+	container := testhelpers.StartPostgresContainer(t)
+
+	newMigrator := func(t *testing.T) *repository.Migrator {
+		m, err := repository.NewMigratorPostgres(container.Dsn())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { m.Close() })
+
+		return m
+	}
+
+	t.Run("should report all migrations as pending on empty database", func(t *testing.T) {
+		// assign
+		m := newMigrator(t)
+
+		// act
+		status, err := m.Status()
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, repository.MigrationStatus{
+			Version: 0,
+			Latest:  10,
+			Pending: []uint{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		}, status)
+	})
+
+	t.Run("should apply pending migrations once across concurrent migrators", func(t *testing.T) {
+		// assign
+		a := newMigrator(t)
+		b := newMigrator(t)
+
+		// act
+		var wg sync.WaitGroup
+		var errA, errB error
+		wg.Add(2)
+		go func() { defer wg.Done(); errA = a.Up() }()
+		go func() { defer wg.Done(); errB = b.Up() }()
+		wg.Wait()
+
+		// assert
+		assert.NoError(t, errA)
+		assert.NoError(t, errB)
+		status, err := a.Status()
+		assert.NoError(t, err)
+		assert.Equal(t, repository.MigrationStatus{Version: 10, Latest: 10}, status)
+	})
+
+	t.Run("should revert migrations", func(t *testing.T) {
+		// assign
+		m := newMigrator(t)
+
+		// act
+		err := m.Down(2)
+
+		// assert
+		assert.NoError(t, err)
+		status, err := m.Status()
+		assert.NoError(t, err)
+		assert.Equal(t, repository.MigrationStatus{Version: 6, Latest: 10, Pending: []uint{7, 8, 9, 10}}, status)
+	})
+
+	t.Run("should return error if more migrations are reverted than applied", func(t *testing.T) {
+		// assign
+		m := newMigrator(t)
+
+		// act
+		err := m.Down(7)
+
+		// assert
+		assert.ErrorContains(t, err, "less than 7 migration(s) applied")
+	})
+}
+
+func TestMigratorPostgres_Down_Integration(t *testing.T) {
+	container := testhelpers.StartPostgresContainer(t)
+
+	m, err := repository.NewMigratorPostgres(container.Dsn())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	pool, err := pgxpool.New(context.Background(), container.Dsn())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	if err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should refuse to drop namespaces in use", func(t *testing.T) {
+		// assign
+		_, err := pool.Exec(context.Background(), "INSERT INTO documentations (namespace, name, content) VALUES ('team', 'a', '')")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		err = m.Down(5)
+
+		// assert
+		assert.ErrorContains(t, err, "refusing to drop column namespace")
+	})
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/flohansen/documenter/sql/sqlite/migrations"
+	sqlitemigrate "github.com/golang-migrate/migrate/v4/database/sqlite3"
+)
+
+// NewMigratorSQLite opens the SQLite database of the connection string, see
+// OpenSQLite.
+func NewMigratorSQLite(dsn string) (*Migrator, error) {
+	db, err := OpenSQLite(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := sqlitemigrate.WithInstance(db, &sqlitemigrate.Config{})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not open database: %w", err)
+	}
+
+	return newMigrator(migrations.FS, "sqlite3", driver)
+}
@@ -0,0 +1,66 @@
+package repository_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/flohansen/documenter/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigratorSQLite(t *testing.T) {
+	newMigrator := func(t *testing.T) (*repository.Migrator, string) {
+		dsn := repository.SQLiteScheme + filepath.Join(t.TempDir(), "documenter.db")
+		m, err := repository.NewMigratorSQLite(dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { m.Close() })
+
+		if err := m.Up(); err != nil {
+			t.Fatal(err)
+		}
+
+		return m, dsn
+	}
+
+	t.Run("should revert all migrations of empty database", func(t *testing.T) {
+		// assign
+		m, _ := newMigrator(t)
+
+		// act
+		err := m.Down(3)
+
+		// assert
+		assert.NoError(t, err)
+		status, err := m.Status()
+		assert.NoError(t, err)
+		assert.Equal(t, uint(0), status.Version)
+	})
+
+	for _, table := range []string{"documentations", "documentations_archive"} {
+		t.Run("should refuse to drop "+table+" with rows", func(t *testing.T) {
+			// assign
+			m, dsn := newMigrator(t)
+			db, err := repository.OpenSQLite(dsn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			if _, err := db.Exec("INSERT INTO " + table + " (name, content) VALUES ('a', '')"); err != nil {
+				t.Fatal(err)
+			}
+
+			// act
+			err = m.Down(3)
+
+			// assert
+			assert.ErrorContains(t, err, "refusing to drop tables documentations and documentations_archive")
+			var n int
+			if err := db.QueryRow("SELECT count(*) FROM " + table).Scan(&n); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, 1, n)
+		})
+	}
+}
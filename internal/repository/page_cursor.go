@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/flohansen/documenter/internal/domain"
+)
+
+// ErrInvalidCursor is returned if a page cursor is malformed or was returned
+// for another sort order.
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+// pageCursor is the position of the last documentation of a page. Cursors are
+// passed to clients as opaque strings.
+type pageCursor struct {
+	Sort      domain.DocumentationSort `json:"s"`
+	Name      string                   `json:"n"`
+	UpdatedAt time.Time                `json:"u,omitzero"` // SortByUpdated only
+}
+
+func (c pageCursor) String() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// parsePageCursor decodes the cursor of a page sorted by sort. An empty
+// cursor is the position before the first documentation.
+func parsePageCursor(s string, sort domain.DocumentationSort) (pageCursor, error) {
+	if len(s) == 0 {
+		return pageCursor{Sort: sort}, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pageCursor{}, ErrInvalidCursor
+	}
+
+	var cursor pageCursor
+	if err := json.Unmarshal(b, &cursor); err != nil || cursor.Sort != sort {
+		return pageCursor{}, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// pageLimit returns the limit of the page within the allowed range.
+func pageLimit(page domain.Page) int {
+	switch {
+	case page.Limit <= 0:
+		return domain.DefaultPageLimit
+	case page.Limit > domain.MaxPageLimit:
+		return domain.MaxPageLimit
+	default:
+		return page.Limit
+	}
+}
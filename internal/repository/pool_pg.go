@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultPostgresDSN is used if no connection string is configured.
+const DefaultPostgresDSN = "postgresql://localhost:5432/postgres"
+
+type poolOptions struct {
+	maxConns       int32
+	minConns       int32
+	connectTimeout time.Duration
+	params         map[string]string
+}
+
+// NewPostgresPool creates a connection pool for the database with the given
+// connection string. Missing connection settings (e.g. the password) are
+// taken from the standard PostgreSQL environment variables like PGPASSWORD.
+func NewPostgresPool(ctx context.Context, dsn string, opts ...PoolOption) (*pgxpool.Pool, error) {
+	config, err := ParsePoolConfig(dsn, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgxpool.NewWithConfig(ctx, config)
+}
+
+// ParsePoolConfig parses the connection string and applies the options.
+func ParsePoolConfig(dsn string, opts ...PoolOption) (*pgxpool.Config, error) {
+	po := poolOptions{
+		params: make(map[string]string),
+	}
+
+	for _, opt := range opts {
+		opt(&po)
+	}
+
+	if len(dsn) == 0 {
+		dsn = DefaultPostgresDSN
+	}
+
+	dsn, err := withParams(dsn, po.params)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse dsn: %w", err)
+	}
+
+	if po.maxConns > 0 {
+		config.MaxConns = po.maxConns
+	}
+	if po.minConns > 0 {
+		config.MinConns = po.minConns
+	}
+	if po.connectTimeout > 0 {
+		config.ConnConfig.ConnectTimeout = po.connectTimeout
+	}
+
+	return config, nil
+}
+
+// withParams sets the parameters in the connection string, which is either a
+// URL or in the keyword/value format.
+func withParams(dsn string, params map[string]string) (string, error) {
+	if len(params) == 0 {
+		return dsn, nil
+	}
+
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", fmt.Errorf("could not parse dsn: %w", err)
+		}
+
+		query := u.Query()
+		for key, value := range params {
+			query.Set(key, value)
+		}
+		u.RawQuery = query.Encode()
+
+		return u.String(), nil
+	}
+
+	// Later keywords take precedence over earlier ones.
+	var sb strings.Builder
+	sb.WriteString(dsn)
+	for key, value := range params {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+		value = strings.ReplaceAll(value, `'`, `\'`)
+		fmt.Fprintf(&sb, " %s='%s'", key, value)
+	}
+
+	return sb.String(), nil
+}
+
+type PoolOption func(*poolOptions)
+
+func WithMaxConns(maxConns int32) PoolOption {
+	return func(po *poolOptions) {
+		po.maxConns = maxConns
+	}
+}
+
+func WithMinConns(minConns int32) PoolOption {
+	return func(po *poolOptions) {
+		po.minConns = minConns
+	}
+}
+
+func WithConnectTimeout(timeout time.Duration) PoolOption {
+	return func(po *poolOptions) {
+		po.connectTimeout = timeout
+	}
+}
+
+// WithTLS sets the TLS mode (sslmode) and certificate files of connections.
+// Empty values keep the settings of the connection string.
+func WithTLS(mode, caFile, certFile, keyFile string) PoolOption {
+	return func(po *poolOptions) {
+		for key, value := range map[string]string{
+			"sslmode":     mode,
+			"sslrootcert": caFile,
+			"sslcert":     certFile,
+			"sslkey":      keyFile,
+		} {
+			if len(value) > 0 {
+				po.params[key] = value
+			}
+		}
+	}
+}
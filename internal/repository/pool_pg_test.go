@@ -0,0 +1,69 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flohansen/documenter/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePoolConfig(t *testing.T) {
+	t.Run("should use default dsn if empty", func(t *testing.T) {
+		// act
+		config, err := repository.ParsePoolConfig("")
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "localhost", config.ConnConfig.Host)
+		assert.Equal(t, uint16(5432), config.ConnConfig.Port)
+		assert.Equal(t, "postgres", config.ConnConfig.Database)
+	})
+
+	t.Run("should apply pool options", func(t *testing.T) {
+		// act
+		config, err := repository.ParsePoolConfig("postgres://user@db:5432/docs",
+			repository.WithMaxConns(10),
+			repository.WithMinConns(2),
+			repository.WithConnectTimeout(3*time.Second))
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, int32(10), config.MaxConns)
+		assert.Equal(t, int32(2), config.MinConns)
+		assert.Equal(t, 3*time.Second, config.ConnConfig.ConnectTimeout)
+	})
+
+	t.Run("should override tls mode of url dsn", func(t *testing.T) {
+		// act
+		config, err := repository.ParsePoolConfig("postgres://user@db:5432/docs?sslmode=require",
+			repository.WithTLS("disable", "", "", ""))
+
+		// assert
+		assert.NoError(t, err)
+		assert.Nil(t, config.ConnConfig.TLSConfig)
+	})
+
+	t.Run("should override tls mode of keyword/value dsn", func(t *testing.T) {
+		// act
+		config, err := repository.ParsePoolConfig("host=db user=user sslmode=disable",
+			repository.WithTLS("require", "", "", ""))
+
+		// assert
+		assert.NoError(t, err)
+		assert.NotNil(t, config.ConnConfig.TLSConfig)
+		assert.Equal(t, "db", config.ConnConfig.Host)
+	})
+
+	t.Run("should take password from environment", func(t *testing.T) {
+		// assign
+		t.Setenv("PGPASSWORD", "secret")
+
+		// act
+		config, err := repository.ParsePoolConfig("postgres://user@db:5432/docs")
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "secret", config.ConnConfig.Password)
+	})
+}
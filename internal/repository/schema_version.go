@@ -0,0 +1,35 @@
+package repository
+
+import "fmt"
+
+// SchemaVersionError reports a database schema not matching the schema
+// version expected by the binary.
+type SchemaVersionError struct {
+	Actual   uint // Version of the last applied migration
+	Expected uint // Version of the latest embedded migration
+	Dirty    bool // Whether the last migration failed
+	Missing  bool // Whether no migration has been applied at all
+}
+
+func (e *SchemaVersionError) Error() string {
+	switch {
+	case e.Missing:
+		return fmt.Sprintf("database schema is not initialized, expected version %d (run \"importer migrate up\" or enable database.autoMigrate)", e.Expected)
+	case e.Dirty:
+		return fmt.Sprintf("database schema version %d is dirty, a migration failed and needs to be fixed manually", e.Actual)
+	case e.Actual < e.Expected:
+		return fmt.Sprintf("database schema version %d is older than expected version %d (run \"importer migrate up\")", e.Actual, e.Expected)
+	default:
+		return fmt.Sprintf("database schema version %d is newer than expected version %d (upgrade the importer)", e.Actual, e.Expected)
+	}
+}
+
+// compareSchemaVersion returns a *SchemaVersionError if the applied schema
+// version is dirty or does not equal the expected version.
+func compareSchemaVersion(actual int64, dirty bool, expected uint) error {
+	if dirty || uint(actual) != expected {
+		return &SchemaVersionError{Actual: uint(actual), Expected: expected, Dirty: dirty}
+	}
+
+	return nil
+}
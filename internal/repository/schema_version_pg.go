@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/flohansen/documenter/sql/migrations"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SchemaVersion returns the version of the latest embedded PostgreSQL
+// migration, which is the schema version this binary expects.
+func SchemaVersion() (uint, error) {
+	return latestVersion(migrations.FS)
+}
+
+// CheckSchemaVersion compares the schema version of the database with the
+// version expected by the binary. It returns a *SchemaVersionError if the
+// schema is missing, dirty, older or newer than expected.
+func CheckSchemaVersion(ctx context.Context, pool *pgxpool.Pool) error {
+	expected, err := SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	var actual int64
+	var dirty bool
+	err = pool.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&actual, &dirty)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.Is(err, pgx.ErrNoRows) || (errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UndefinedTable) {
+			return &SchemaVersionError{Expected: expected, Missing: true}
+		}
+
+		return fmt.Errorf("could not read schema version: %w", err)
+	}
+
+	return compareSchemaVersion(actual, dirty, expected)
+}
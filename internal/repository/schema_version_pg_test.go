@@ -0,0 +1,125 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flohansen/documenter/internal/repository"
+	"github.com/flohansen/documenter/test/testhelpers"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaVersion(t *testing.T) {
+	t.Run("should return version of latest migration", func(t *testing.T) {
+		// act
+		version, err := repository.SchemaVersion()
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, uint(10), version)
+	})
+}
+
+func TestSchemaVersionError_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      repository.SchemaVersionError
+		expected string
+	}{
+		{
+			name:     "should report missing schema",
+			err:      repository.SchemaVersionError{Expected: 4, Missing: true},
+			expected: `database schema is not initialized, expected version 4 (run "importer migrate up" or enable database.autoMigrate)`,
+		},
+		{
+			name:     "should report dirty schema",
+			err:      repository.SchemaVersionError{Actual: 3, Expected: 4, Dirty: true},
+			expected: "database schema version 3 is dirty, a migration failed and needs to be fixed manually",
+		},
+		{
+			name:     "should report older schema",
+			err:      repository.SchemaVersionError{Actual: 2, Expected: 4},
+			expected: `database schema version 2 is older than expected version 4 (run "importer migrate up")`,
+		},
+		{
+			name:     "should report newer schema",
+			err:      repository.SchemaVersionError{Actual: 5, Expected: 4},
+			expected: "database schema version 5 is newer than expected version 4 (upgrade the importer)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			msg := tt.err.Error()
+
+			// assert
+			assert.Equal(t, tt.expected, msg)
+		})
+	}
+}
+
+func TestCheckSchemaVersion_Integration(t *testing.T) {
+	container := testhelpers.StartPostgresContainer(t)
+
+	pool, err := pgxpool.New(context.Background(), container.Dsn())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	t.Run("should report missing schema", func(t *testing.T) {
+		// act
+		err := repository.CheckSchemaVersion(context.Background(), pool)
+
+		// assert
+		var versionErr *repository.SchemaVersionError
+		assert.ErrorAs(t, err, &versionErr)
+		assert.True(t, versionErr.Missing)
+	})
+
+	t.Run("should accept expected schema", func(t *testing.T) {
+		// assign
+		m, err := repository.NewMigratorPostgres(container.Dsn())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer m.Close()
+		if err := m.Up(); err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		err = repository.CheckSchemaVersion(context.Background(), pool)
+
+		// assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("should reject newer schema", func(t *testing.T) {
+		// assign
+		if _, err := pool.Exec(context.Background(), "UPDATE schema_migrations SET version = 11"); err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		err := repository.CheckSchemaVersion(context.Background(), pool)
+
+		// assert
+		assert.Equal(t, &repository.SchemaVersionError{Actual: 11, Expected: 10}, err)
+	})
+
+	t.Run("should reject older schema", func(t *testing.T) {
+		// assign
+		if _, err := pool.Exec(context.Background(), "UPDATE schema_migrations SET version = 3"); err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		err := repository.CheckSchemaVersion(context.Background(), pool)
+
+		// assert
+		assert.Equal(t, &repository.SchemaVersionError{Actual: 3, Expected: 8}, err)
+	})
+}
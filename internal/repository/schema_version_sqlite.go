@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/flohansen/documenter/sql/sqlite/migrations"
+)
+
+// SchemaVersionSQLite returns the version of the latest embedded SQLite
+// migration, which is the schema version this binary expects.
+func SchemaVersionSQLite() (uint, error) {
+	return latestVersion(migrations.FS)
+}
+
+// CheckSchemaVersionSQLite is the SQLite equivalent of CheckSchemaVersion.
+func CheckSchemaVersionSQLite(ctx context.Context, db *sql.DB) error {
+	expected, err := SchemaVersionSQLite()
+	if err != nil {
+		return err
+	}
+
+	var tables int
+	err = db.QueryRowContext(ctx, "SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'").Scan(&tables)
+	if err != nil {
+		return fmt.Errorf("could not read schema version: %w", err)
+	}
+	if tables == 0 {
+		return &SchemaVersionError{Expected: expected, Missing: true}
+	}
+
+	var actual int64
+	var dirty bool
+	err = db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&actual, &dirty)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &SchemaVersionError{Expected: expected, Missing: true}
+		}
+
+		return fmt.Errorf("could not read schema version: %w", err)
+	}
+
+	return compareSchemaVersion(actual, dirty, expected)
+}
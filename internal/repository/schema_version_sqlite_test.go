@@ -0,0 +1,64 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/flohansen/documenter/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSchemaVersionSQLite(t *testing.T) {
+	dsn := repository.SQLiteScheme + filepath.Join(t.TempDir(), "documenter.db")
+
+	db, err := repository.OpenSQLite(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	expected, err := repository.SchemaVersionSQLite()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should report missing schema", func(t *testing.T) {
+		// act
+		err := repository.CheckSchemaVersionSQLite(context.Background(), db)
+
+		// assert
+		assert.Equal(t, &repository.SchemaVersionError{Expected: expected, Missing: true}, err)
+	})
+
+	t.Run("should accept expected schema", func(t *testing.T) {
+		// assign
+		m, err := repository.NewMigratorSQLite(dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer m.Close()
+		if err := m.Up(); err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		err = repository.CheckSchemaVersionSQLite(context.Background(), db)
+
+		// assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("should reject newer schema", func(t *testing.T) {
+		// assign
+		if _, err := db.Exec("UPDATE schema_migrations SET version = version + 1"); err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		err := repository.CheckSchemaVersionSQLite(context.Background(), db)
+
+		// assert
+		assert.Equal(t, &repository.SchemaVersionError{Actual: expected + 1, Expected: expected}, err)
+	})
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/flohansen/documenter/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SectionLockerPostgres claims sections using PostgreSQL session level
+// advisory locks. All locks are held by a single dedicated connection, so they
+// are released by the database as soon as the process or its connection dies,
+// which lets another replica take over. A section may be claimed by multiple
+// owners of this locker and is released once the last owner unlocked it.
+type SectionLockerPostgres struct {
+	pool *pgxpool.Pool
+
+	mu   sync.Mutex
+	conn *pgx.Conn
+	held map[string]map[string]struct{} // Owners of the held locks by section
+}
+
+func NewSectionLockerPostgres(pool *pgxpool.Pool) *SectionLockerPostgres {
+	return &SectionLockerPostgres{
+		pool: pool,
+		held: make(map[string]map[string]struct{}),
+	}
+}
+
+// TryLock tries to claim the section with the given name for the owner. It
+// returns true if the section is claimed by this locker, either by now or by a
+// previous call of any owner.
+func (l *SectionLockerPostgres) TryLock(ctx context.Context, name, owner string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	conn, err := l.connection(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if owners, ok := l.held[name]; ok {
+		// The lock is bound to the session, so it is still held as long as
+		// the connection is alive.
+		if err := conn.Ping(ctx); err != nil {
+			l.reset()
+			return false, fmt.Errorf("lock connection lost: %w", err)
+		}
+
+		owners[owner] = struct{}{}
+		return true, nil
+	}
+
+	ok, err := database.New(conn).TryLockSection(ctx, name)
+	if err != nil {
+		l.reset()
+		return false, fmt.Errorf("try lock error: %w", err)
+	}
+
+	if ok {
+		l.held[name] = map[string]struct{}{owner: {}}
+	}
+
+	return ok, nil
+}
+
+// Unlock releases the claim of the owner on the section with the given name.
+// The section is released once no other owner of this locker claims it.
+func (l *SectionLockerPostgres) Unlock(ctx context.Context, name, owner string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	owners, ok := l.held[name]
+	if !ok || l.conn == nil {
+		return nil
+	}
+
+	delete(owners, owner)
+	if len(owners) > 0 {
+		return nil
+	}
+
+	delete(l.held, name)
+	if _, err := database.New(l.conn).UnlockSection(ctx, name); err != nil {
+		l.reset()
+		return fmt.Errorf("unlock error: %w", err)
+	}
+
+	return nil
+}
+
+// Close releases all sections by closing the dedicated connection.
+func (l *SectionLockerPostgres) Close(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+
+	err := l.conn.Close(ctx)
+	l.conn = nil
+	l.held = make(map[string]map[string]struct{})
+	return err
+}
+
+// connection returns the dedicated connection holding the locks. The
+// connection is taken out of the pool, so the locks are never shared with
+// other pool users.
+func (l *SectionLockerPostgres) connection(ctx context.Context) (*pgx.Conn, error) {
+	if l.conn != nil {
+		return l.conn, nil
+	}
+
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not acquire lock connection: %w", err)
+	}
+
+	l.conn = conn.Hijack()
+	return l.conn, nil
+}
+
+// reset drops the dedicated connection and forgets all held locks. Closing the
+// connection ensures the database releases any locks which may still be held.
+func (l *SectionLockerPostgres) reset() {
+	if l.conn != nil {
+		l.conn.Close(context.Background())
+	}
+
+	l.conn = nil
+	l.held = make(map[string]map[string]struct{})
+}
@@ -0,0 +1,114 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flohansen/documenter/internal/repository"
+	"github.com/flohansen/documenter/test/testhelpers"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSectionLockerPostgres_Integration(t *testing.T) {
+	container := testhelpers.StartPostgresContainer(t)
+
+	pool, err := pgxpool.New(context.Background(), container.Dsn())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	t.Run("should claim section for one locker at a time", func(t *testing.T) {
+		// assign
+		a := repository.NewSectionLockerPostgres(pool)
+		defer a.Close(context.Background())
+		b := repository.NewSectionLockerPostgres(pool)
+		defer b.Close(context.Background())
+
+		// act
+		okA, errA := a.TryLock(context.Background(), "section", "owner")
+		okB, errB := b.TryLock(context.Background(), "section", "owner")
+
+		// assert
+		assert.NoError(t, errA)
+		assert.NoError(t, errB)
+		assert.True(t, okA)
+		assert.False(t, okB)
+	})
+
+	t.Run("should keep claim on repeated locking", func(t *testing.T) {
+		// assign
+		a := repository.NewSectionLockerPostgres(pool)
+		defer a.Close(context.Background())
+
+		// act
+		_, _ = a.TryLock(context.Background(), "section", "owner")
+		ok, err := a.TryLock(context.Background(), "section", "owner")
+
+		// assert
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("should allow other locker to claim section after unlock", func(t *testing.T) {
+		// assign
+		a := repository.NewSectionLockerPostgres(pool)
+		defer a.Close(context.Background())
+		b := repository.NewSectionLockerPostgres(pool)
+		defer b.Close(context.Background())
+
+		_, _ = a.TryLock(context.Background(), "section", "owner")
+
+		// act
+		err := a.Unlock(context.Background(), "section", "owner")
+		ok, errB := b.TryLock(context.Background(), "section", "owner")
+
+		// assert
+		assert.NoError(t, err)
+		assert.NoError(t, errB)
+		assert.True(t, ok)
+	})
+
+	t.Run("should keep claim until last owner unlocked", func(t *testing.T) {
+		// assign
+		a := repository.NewSectionLockerPostgres(pool)
+		defer a.Close(context.Background())
+		b := repository.NewSectionLockerPostgres(pool)
+		defer b.Close(context.Background())
+
+		_, _ = a.TryLock(context.Background(), "section", "old")
+		_, _ = a.TryLock(context.Background(), "section", "new")
+
+		// act
+		err := a.Unlock(context.Background(), "section", "old")
+		okOld, errOld := b.TryLock(context.Background(), "section", "owner")
+		_ = a.Unlock(context.Background(), "section", "new")
+		okNew, errNew := b.TryLock(context.Background(), "section", "owner")
+
+		// assert
+		assert.NoError(t, err)
+		assert.NoError(t, errOld)
+		assert.NoError(t, errNew)
+		assert.False(t, okOld)
+		assert.True(t, okNew)
+	})
+
+	t.Run("should allow other locker to claim section after close", func(t *testing.T) {
+		// assign
+		a := repository.NewSectionLockerPostgres(pool)
+		b := repository.NewSectionLockerPostgres(pool)
+		defer b.Close(context.Background())
+
+		_, _ = a.TryLock(context.Background(), "section", "owner")
+
+		// act
+		err := a.Close(context.Background())
+		ok, errB := b.TryLock(context.Background(), "section", "owner")
+
+		// assert
+		assert.NoError(t, err)
+		assert.NoError(t, errB)
+		assert.True(t, ok)
+	})
+}
@@ -8,18 +8,26 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/go-git/go-git/v5/storage/memory"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const tracerName = "github.com/flohansen/documenter/internal/scraper"
+
 type GitScraper struct {
 	name    string
 	repoURL string
 	sshKey  *string
+	tracer  trace.Tracer
 }
 
 func NewGitScraper(name string, repoURL string, opts ...GitScraperOption) *GitScraper {
 	gs := &GitScraper{
 		name:    name,
 		repoURL: repoURL,
+		tracer:  otel.Tracer(tracerName),
 	}
 
 	for _, opt := range opts {
@@ -34,6 +42,18 @@ func (s *GitScraper) Name() string {
 }
 
 func (s *GitScraper) Scrape(ctx context.Context) ([]byte, error) {
+	repo, err := s.clone(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.readFile(ctx, repo, "README.md")
+}
+
+func (s *GitScraper) clone(ctx context.Context) (_ *git.Repository, err error) {
+	ctx, span := s.tracer.Start(ctx, "clone", trace.WithAttributes(attribute.String("git.url", s.repoURL)))
+	defer func() { endSpan(span, err) }()
+
 	cloneOptions, err := s.cloneOptionsForSection()
 	if err != nil {
 		return nil, fmt.Errorf("clone options setup error: %w", err)
@@ -44,6 +64,13 @@ func (s *GitScraper) Scrape(ctx context.Context) ([]byte, error) {
 		return nil, fmt.Errorf("clone error: %s", err)
 	}
 
+	return repo, nil
+}
+
+func (s *GitScraper) readFile(ctx context.Context, repo *git.Repository, name string) (_ []byte, err error) {
+	_, span := s.tracer.Start(ctx, "read file", trace.WithAttributes(attribute.String("file.name", name)))
+	defer func() { endSpan(span, err) }()
+
 	ref, err := repo.Head()
 	if err != nil {
 		return nil, fmt.Errorf("repository head error: %s", err)
@@ -54,7 +81,7 @@ func (s *GitScraper) Scrape(ctx context.Context) ([]byte, error) {
 		return nil, fmt.Errorf("repository commit error: %s", err)
 	}
 
-	file, err := commit.File("README.md")
+	file, err := commit.File(name)
 	if err != nil {
 		return nil, fmt.Errorf("commit file error: %s", err)
 	}
@@ -73,6 +100,15 @@ func (s *GitScraper) Scrape(ctx context.Context) ([]byte, error) {
 	return b, nil
 }
 
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
 func (s *GitScraper) cloneOptionsForSection() (git.CloneOptions, error) {
 	cloneOptions := git.CloneOptions{
 		URL:   s.repoURL,
@@ -100,3 +136,13 @@ func WithSSHKey(sshKey string) GitScraperOption {
 		}
 	}
 }
+
+// WithTracer records the spans of the scraper using the tracer instead of
+// the global tracer.
+func WithTracer(tracer trace.Tracer) GitScraperOption {
+	return func(gs *GitScraper) {
+		if tracer != nil {
+			gs.tracer = tracer
+		}
+	}
+}
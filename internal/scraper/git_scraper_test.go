@@ -2,10 +2,18 @@ package scraper_test
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/flohansen/documenter/internal/scraper"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestGitScraper_Scrape(t *testing.T) {
@@ -20,4 +28,76 @@ func TestGitScraper_Scrape(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Greater(t, len(md), 0)
 	})
+	t.Run("should record spans using the tracer", func(t *testing.T) {
+		// assign
+		dir := initRepository(t, map[string]string{"README.md": "# Readme"})
+		recorder := tracetest.NewSpanRecorder()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		scpr := scraper.NewGitScraper("name", dir, scraper.WithTracer(tp.Tracer("test")))
+
+		// act
+		md, err := scpr.Scrape(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "# Readme", string(md))
+		var names []string
+		for _, span := range recorder.Ended() {
+			names = append(names, span.Name())
+			assert.Equal(t, codes.Unset, span.Status().Code)
+		}
+		assert.Equal(t, []string{"clone", "read file"}, names)
+	})
+
+	t.Run("should record error of failed span", func(t *testing.T) {
+		// assign
+		dir := initRepository(t, map[string]string{"CONTRIBUTING.md": "# Contributing"})
+		recorder := tracetest.NewSpanRecorder()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		scpr := scraper.NewGitScraper("name", dir, scraper.WithTracer(tp.Tracer("test")))
+
+		// act
+		_, err := scpr.Scrape(context.Background())
+
+		// assert
+		assert.Error(t, err)
+		spans := recorder.Ended()
+		if assert.Len(t, spans, 2) {
+			assert.Equal(t, codes.Unset, spans[0].Status().Code)
+			assert.Equal(t, "read file", spans[1].Name())
+			assert.Equal(t, codes.Error, spans[1].Status().Code)
+		}
+	})
+}
+
+// initRepository creates a local repository with a single commit containing
+// the files and returns its directory.
+func initRepository(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := worktree.Commit("initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
 }
@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const defaultServiceName = "documenter-importer"
+
+type providerOptions struct {
+	serviceName string
+	insecure    bool
+}
+
+// NewProvider creates a tracer provider which exports spans via OTLP/HTTP to
+// the collector listening at endpoint (host:port). The caller is responsible
+// for shutting down the provider to flush pending spans.
+func NewProvider(ctx context.Context, endpoint string, opts ...ProviderOption) (*sdktrace.TracerProvider, error) {
+	po := providerOptions{
+		serviceName: defaultServiceName,
+	}
+
+	for _, opt := range opts {
+		opt(&po)
+	}
+
+	exporterOptions := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(endpoint),
+	}
+	if po.insecure {
+		exporterOptions = append(exporterOptions, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, exporterOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create otlp exporter: %w", err)
+	}
+
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(po.serviceName),
+	)
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	), nil
+}
+
+type ProviderOption func(*providerOptions)
+
+func WithServiceName(serviceName string) ProviderOption {
+	return func(po *providerOptions) {
+		if len(serviceName) > 0 {
+			po.serviceName = serviceName
+		}
+	}
+}
+
+func WithInsecure(insecure bool) ProviderOption {
+	return func(po *providerOptions) {
+		po.insecure = insecure
+	}
+}
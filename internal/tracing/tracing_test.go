@@ -0,0 +1,49 @@
+package tracing_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flohansen/documenter/internal/tracing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProvider(t *testing.T) {
+	t.Run("should export spans to the collector endpoint", func(t *testing.T) {
+		// assign
+		requests := make(chan *http.Request, 1)
+		collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.Copy(io.Discard, r.Body)
+			requests <- r
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer collector.Close()
+
+		endpoint := strings.TrimPrefix(collector.URL, "http://")
+		tp, err := tracing.NewProvider(context.Background(), endpoint,
+			tracing.WithServiceName("test"),
+			tracing.WithInsecure(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		_, span := tp.Tracer("test").Start(context.Background(), "span")
+		span.End()
+		err = tp.Shutdown(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		select {
+		case r := <-requests:
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/v1/traces", r.URL.Path)
+		default:
+			t.Fatal("collector did not receive any spans")
+		}
+	})
+}
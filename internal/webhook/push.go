@@ -0,0 +1,155 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxPushPayloadSize is the largest accepted push payload, which matches the
+// limit of GitHub.
+const maxPushPayloadSize = 25 << 20
+
+// Trigger scrapes the sections of the repositories with the given URLs and
+// returns the qualified names of the triggered sections and of the sections
+// which are claimed by another replica and therefore not triggered.
+type Trigger func(urls []string) (triggered, claimed []string)
+
+// PushHandler accepts push webhooks of GitHub, Gitea and GitLab and triggers
+// the sections of the pushed repository. Requests are authenticated using
+// the secret configured for the webhook at the hosting service: GitHub and
+// Gitea sign the payload with HMAC-SHA256, GitLab sends the secret as token.
+type PushHandler struct {
+	secret  string
+	trigger Trigger
+}
+
+// pushResponse is the response to a verified push.
+type pushResponse struct {
+	Sections []string `json:"sections"`          // Triggered sections
+	Claimed  []string `json:"claimed,omitempty"` // Sections claimed by another replica
+}
+
+// pushPayload contains the repository URLs of push payloads. GitHub and Gitea
+// describe the repository in "repository", GitLab in "project".
+type pushPayload struct {
+	Repository struct {
+		CloneURL string `json:"clone_url"`
+		SSHURL   string `json:"ssh_url"`
+		HTMLURL  string `json:"html_url"`
+	} `json:"repository"`
+	Project struct {
+		GitHTTPURL string `json:"git_http_url"`
+		GitSSHURL  string `json:"git_ssh_url"`
+		WebURL     string `json:"web_url"`
+	} `json:"project"`
+}
+
+// urls returns the non-empty repository URLs of the payload.
+func (p pushPayload) urls() []string {
+	var urls []string
+	for _, u := range []string{
+		p.Repository.CloneURL,
+		p.Repository.SSHURL,
+		p.Repository.HTMLURL,
+		p.Project.GitHTTPURL,
+		p.Project.GitSSHURL,
+		p.Project.WebURL,
+	} {
+		if len(u) > 0 {
+			urls = append(urls, u)
+		}
+	}
+
+	return urls
+}
+
+// NewPushHandler creates a handler verifying pushes with the secret and
+// passing the URLs of pushed repositories to trigger.
+func NewPushHandler(secret string, trigger Trigger) *PushHandler {
+	return &PushHandler{
+		secret:  secret,
+		trigger: trigger,
+	}
+}
+
+// ServeHTTP implements http.Handler. Verified pushes are answered with 202
+// and the triggered sections. If no section is triggered, pushes are answered
+// with 409 if sections of the repository are claimed by another replica and
+// with 404 otherwise. Other verified events are answered with 204.
+func (h *PushHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPushPayloadSize))
+	if err != nil {
+		http.Error(w, "could not read payload", http.StatusBadRequest)
+		return
+	}
+
+	var event string
+	var verified bool
+	switch {
+	case len(r.Header.Get("X-Gitea-Event")) > 0:
+		// Gitea sends the GitHub headers as well, so it is checked first.
+		event = r.Header.Get("X-Gitea-Event")
+		verified = h.verifySignature("sha256="+r.Header.Get("X-Gitea-Signature"), body)
+	case len(r.Header.Get("X-GitHub-Event")) > 0:
+		event = r.Header.Get("X-GitHub-Event")
+		verified = h.verifySignature(r.Header.Get("X-Hub-Signature-256"), body)
+	case len(r.Header.Get("X-Gitlab-Event")) > 0:
+		event = r.Header.Get("X-Gitlab-Event")
+		verified = subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Gitlab-Token")), []byte(h.secret)) == 1
+	default:
+		http.Error(w, "unsupported webhook", http.StatusBadRequest)
+		return
+	}
+
+	if !verified {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	if event != "push" && event != "Push Hook" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var payload pushPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	sections, claimed := h.trigger(payload.urls())
+	if sections == nil {
+		sections = []string{}
+	}
+
+	status := http.StatusAccepted
+	if len(sections) == 0 {
+		status = http.StatusNotFound
+		if len(claimed) > 0 {
+			status = http.StatusConflict
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(pushResponse{Sections: sections, Claimed: claimed})
+}
+
+// verifySignature reports whether the signature in the form "sha256=<hex>"
+// matches the payload.
+func (h *PushHandler) verifySignature(signature string, payload []byte) bool {
+	if !strings.HasPrefix(signature, "sha256=") || len(signature) == len("sha256=") {
+		return false
+	}
+
+	return hmac.Equal([]byte(signature), []byte(Sign(h.secret, payload)))
+}
@@ -0,0 +1,176 @@
+package webhook_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flohansen/documenter/internal/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushHandler(t *testing.T) {
+	githubPayload := `{"repository":{"clone_url":"https://github.com/org/repo.git","ssh_url":"git@github.com:org/repo.git","html_url":"https://github.com/org/repo"}}`
+	gitlabPayload := `{"project":{"git_http_url":"https://gitlab.com/org/repo.git","git_ssh_url":"git@gitlab.com:org/repo.git","web_url":"https://gitlab.com/org/repo"}}`
+
+	newRequest := func(payload string, header map[string]string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/hooks/push", strings.NewReader(payload))
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		return req
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		payload      string
+		header       map[string]string
+		triggered    []string
+		claimed      []string
+		expectedCode int
+		expectedURLs []string
+		expectedBody string
+	}{
+		{
+			name:    "should trigger repository of github push",
+			payload: githubPayload,
+			header: map[string]string{
+				"X-GitHub-Event":      "push",
+				"X-Hub-Signature-256": webhook.Sign("secret", []byte(githubPayload)),
+			},
+			triggered:    []string{"repo"},
+			expectedCode: http.StatusAccepted,
+			expectedURLs: []string{"https://github.com/org/repo.git", "git@github.com:org/repo.git", "https://github.com/org/repo"},
+			expectedBody: `{"sections":["repo"]}` + "\n",
+		},
+		{
+			name:    "should trigger repository of gitea push",
+			payload: githubPayload,
+			header: map[string]string{
+				"X-GitHub-Event":    "push",
+				"X-Gitea-Event":     "push",
+				"X-Gitea-Signature": strings.TrimPrefix(webhook.Sign("secret", []byte(githubPayload)), "sha256="),
+			},
+			triggered:    []string{"repo"},
+			expectedCode: http.StatusAccepted,
+			expectedURLs: []string{"https://github.com/org/repo.git", "git@github.com:org/repo.git", "https://github.com/org/repo"},
+			expectedBody: `{"sections":["repo"]}` + "\n",
+		},
+		{
+			name:    "should trigger repository of gitlab push",
+			payload: gitlabPayload,
+			header: map[string]string{
+				"X-Gitlab-Event": "Push Hook",
+				"X-Gitlab-Token": "secret",
+			},
+			triggered:    []string{"repo"},
+			expectedCode: http.StatusAccepted,
+			expectedURLs: []string{"https://gitlab.com/org/repo.git", "git@gitlab.com:org/repo.git", "https://gitlab.com/org/repo"},
+			expectedBody: `{"sections":["repo"]}` + "\n",
+		},
+		{
+			name:    "should report sections claimed by another replica",
+			payload: githubPayload,
+			header: map[string]string{
+				"X-GitHub-Event":      "push",
+				"X-Hub-Signature-256": webhook.Sign("secret", []byte(githubPayload)),
+			},
+			claimed:      []string{"repo"},
+			expectedCode: http.StatusConflict,
+			expectedURLs: []string{"https://github.com/org/repo.git", "git@github.com:org/repo.git", "https://github.com/org/repo"},
+			expectedBody: `{"sections":[],"claimed":["repo"]}` + "\n",
+		},
+		{
+			name:    "should report push without running sections",
+			payload: githubPayload,
+			header: map[string]string{
+				"X-GitHub-Event":      "push",
+				"X-Hub-Signature-256": webhook.Sign("secret", []byte(githubPayload)),
+			},
+			expectedCode: http.StatusNotFound,
+			expectedURLs: []string{"https://github.com/org/repo.git", "git@github.com:org/repo.git", "https://github.com/org/repo"},
+			expectedBody: `{"sections":[]}` + "\n",
+		},
+		{
+			name:    "should ignore other events",
+			payload: `{"zen":"Keep it logically awesome."}`,
+			header: map[string]string{
+				"X-GitHub-Event":      "ping",
+				"X-Hub-Signature-256": webhook.Sign("secret", []byte(`{"zen":"Keep it logically awesome."}`)),
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:    "should reject invalid github signature",
+			payload: githubPayload,
+			header: map[string]string{
+				"X-GitHub-Event":      "push",
+				"X-Hub-Signature-256": webhook.Sign("other", []byte(githubPayload)),
+			},
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: "invalid signature\n",
+		},
+		{
+			name:    "should reject missing gitea signature",
+			payload: githubPayload,
+			header: map[string]string{
+				"X-GitHub-Event":      "push",
+				"X-Gitea-Event":       "push",
+				"X-Hub-Signature-256": webhook.Sign("secret", []byte(githubPayload)),
+			},
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: "invalid signature\n",
+		},
+		{
+			name:    "should reject invalid gitlab token",
+			payload: gitlabPayload,
+			header: map[string]string{
+				"X-Gitlab-Event": "Push Hook",
+				"X-Gitlab-Token": "other",
+			},
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: "invalid signature\n",
+		},
+		{
+			name:         "should reject unsupported webhooks",
+			payload:      githubPayload,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "unsupported webhook\n",
+		},
+		{
+			name:         "should reject other methods",
+			method:       http.MethodGet,
+			expectedCode: http.StatusMethodNotAllowed,
+			expectedBody: "method not allowed\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// assign
+			var urls []string
+			handler := webhook.NewPushHandler("secret", func(u []string) ([]string, []string) {
+				urls = u
+				return tt.triggered, tt.claimed
+			})
+
+			req := newRequest(tt.payload, tt.header)
+			if len(tt.method) > 0 {
+				req.Method = tt.method
+			}
+			rec := httptest.NewRecorder()
+
+			// act
+			handler.ServeHTTP(rec, req)
+
+			// assert
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Equal(t, tt.expectedURLs, urls)
+			if len(tt.expectedBody) > 0 {
+				assert.Equal(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
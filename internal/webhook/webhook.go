@@ -0,0 +1,329 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// EventDocumentationChanged is sent after a documentation was created or its
+// content changed.
+const EventDocumentationChanged = "documentation.changed"
+
+// Headers of every delivery. The signature header is only set for hooks with
+// a secret.
+const (
+	EventHeader     = "X-Documenter-Event"
+	DeliveryHeader  = "X-Documenter-Delivery"
+	SignatureHeader = "X-Documenter-Signature-256"
+)
+
+const (
+	defaultMaxAttempts = 5
+	defaultBackoff     = 5 * time.Second
+	defaultQueueSize   = 100
+	defaultLogSize     = 100
+	defaultTimeout     = 10 * time.Second
+)
+
+// errQueueFull is recorded for deliveries which could not be queued.
+var errQueueFull = errors.New("delivery queue is full")
+
+// Hook is a receiver of webhook deliveries.
+type Hook struct {
+	URL    string // Endpoint receiving the POST requests
+	Secret string // Secret used to sign the payloads (optional)
+}
+
+// Event is the payload of a delivery.
+type Event struct {
+	Type      string    `json:"event"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Hash      string    `json:"hash"`
+	Time      time.Time `json:"time"`
+}
+
+// DeliveryStatus represents the outcome of a delivery attempt.
+type DeliveryStatus string
+
+const (
+	// DeliveryStatusDelivered means the receiver accepted the delivery
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	// DeliveryStatusRetrying means the attempt failed and is retried later
+	DeliveryStatusRetrying DeliveryStatus = "retrying"
+	// DeliveryStatusFailed means the last attempt failed and the delivery is given up
+	DeliveryStatusFailed DeliveryStatus = "failed"
+	// DeliveryStatusDropped means the delivery could not be queued
+	DeliveryStatusDropped DeliveryStatus = "dropped"
+)
+
+// Delivery is an entry of the delivery log describing a single attempt.
+type Delivery struct {
+	ID         string         `json:"id"`                   // ID shared by all attempts of the delivery
+	URL        string         `json:"url"`                  // URL of the hook
+	Event      Event          `json:"event"`                // Delivered event
+	Attempt    int            `json:"attempt"`              // Number of the attempt, starting at 1
+	Status     DeliveryStatus `json:"status"`               // Outcome of the attempt
+	StatusCode int            `json:"statusCode,omitempty"` // HTTP status code of the response (0 if there was none)
+	Error      string         `json:"error,omitempty"`      // Reason of a failed attempt
+	Time       time.Time      `json:"time"`                 // Time the attempt finished
+}
+
+// Sign returns the signature of the payload in the form "sha256=<hex>", i.e.
+// the HMAC-SHA256 of the payload keyed by the secret.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Logger logs failed delivery attempts.
+type Logger interface {
+	Warn(msg string, args ...any)
+}
+
+// Dispatcher delivers events to hooks in the background. Failed deliveries
+// are retried with an exponential backoff, and every attempt is recorded in
+// a delivery log of bounded size.
+type Dispatcher struct {
+	client      *http.Client
+	logger      Logger
+	maxAttempts int
+	backoff     time.Duration
+	logSize     int
+	queue       chan delivery
+
+	mu  sync.Mutex
+	log []Delivery
+}
+
+// delivery is a queued delivery of an encoded event to a hook.
+type delivery struct {
+	id      string
+	hook    Hook
+	event   Event
+	payload []byte
+	attempt int
+}
+
+// Option configures a Dispatcher.
+type Option func(*Dispatcher)
+
+// WithHTTPClient sets the client sending the deliveries.
+func WithHTTPClient(client *http.Client) Option {
+	return func(d *Dispatcher) {
+		d.client = client
+	}
+}
+
+// WithLogger logs failed delivery attempts to the logger.
+func WithLogger(logger Logger) Option {
+	return func(d *Dispatcher) {
+		d.logger = logger
+	}
+}
+
+// WithMaxAttempts sets how often a delivery is attempted before it is given
+// up (defaults to 5).
+func WithMaxAttempts(n int) Option {
+	return func(d *Dispatcher) {
+		if n > 0 {
+			d.maxAttempts = n
+		}
+	}
+}
+
+// WithBackoff sets the delay before the first retry, which is doubled for
+// each further retry (defaults to 5s).
+func WithBackoff(backoff time.Duration) Option {
+	return func(d *Dispatcher) {
+		if backoff > 0 {
+			d.backoff = backoff
+		}
+	}
+}
+
+// WithQueueSize sets how many deliveries can be queued before new deliveries
+// are dropped (defaults to 100).
+func WithQueueSize(n int) Option {
+	return func(d *Dispatcher) {
+		if n > 0 {
+			d.queue = make(chan delivery, n)
+		}
+	}
+}
+
+// WithLogSize sets how many attempts are kept in the delivery log (defaults
+// to 100).
+func WithLogSize(n int) Option {
+	return func(d *Dispatcher) {
+		if n > 0 {
+			d.logSize = n
+		}
+	}
+}
+
+// NewDispatcher creates a dispatcher. Deliveries are only sent while Run is
+// running.
+func NewDispatcher(opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		client:      &http.Client{Timeout: defaultTimeout},
+		logger:      slog.New(slog.DiscardHandler),
+		maxAttempts: defaultMaxAttempts,
+		backoff:     defaultBackoff,
+		logSize:     defaultLogSize,
+		queue:       make(chan delivery, defaultQueueSize),
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Send queues the delivery of the event to the hook without blocking. If the
+// queue is full, the delivery is dropped and recorded in the delivery log.
+func (d *Dispatcher) Send(hook Hook, event Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		d.record(delivery{hook: hook, event: event, attempt: 1}, DeliveryStatusFailed, 0, err)
+		return
+	}
+
+	del := delivery{
+		id:      newDeliveryID(),
+		hook:    hook,
+		event:   event,
+		payload: payload,
+		attempt: 1,
+	}
+
+	select {
+	case d.queue <- del:
+	default:
+		d.record(del, DeliveryStatusDropped, 0, errQueueFull)
+	}
+}
+
+// Run sends the queued deliveries one after another until the context is
+// cancelled. Pending retries are discarded when the context is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case del := <-d.queue:
+			d.deliver(ctx, del)
+		}
+	}
+}
+
+// Deliveries returns the delivery log ordered from the oldest to the latest
+// attempt.
+func (d *Dispatcher) Deliveries() []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]Delivery(nil), d.log...)
+}
+
+// deliver sends a single attempt of the delivery and schedules a retry if it
+// failed and attempts are left.
+func (d *Dispatcher) deliver(ctx context.Context, del delivery) {
+	code, err := d.post(ctx, del)
+	if err == nil {
+		d.record(del, DeliveryStatusDelivered, code, nil)
+		return
+	}
+
+	if del.attempt >= d.maxAttempts {
+		d.record(del, DeliveryStatusFailed, code, err)
+		d.logger.Warn("webhook delivery failed", "id", del.id, "url", del.hook.URL, "attempts", del.attempt, "error", err)
+		return
+	}
+
+	d.record(del, DeliveryStatusRetrying, code, err)
+	delay := d.backoff << (del.attempt - 1)
+	d.logger.Warn("webhook delivery attempt failed", "id", del.id, "url", del.hook.URL, "attempt", del.attempt, "delay", delay, "error", err)
+	del.attempt++
+	time.AfterFunc(delay, func() {
+		select {
+		case <-ctx.Done():
+		case d.queue <- del:
+		}
+	})
+}
+
+// post sends the delivery and returns the status code of the response.
+// Responses without a 2xx status code are errors.
+func (d *Dispatcher) post(ctx context.Context, del delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, del.hook.URL, bytes.NewReader(del.payload))
+	if err != nil {
+		return 0, fmt.Errorf("could not create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, del.event.Type)
+	req.Header.Set(DeliveryHeader, del.id)
+	if len(del.hook.Secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(del.hook.Secret, del.payload))
+	}
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request error: %w", err)
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+// record appends the attempt to the delivery log and drops the oldest
+// entries exceeding the log size.
+func (d *Dispatcher) record(del delivery, status DeliveryStatus, code int, err error) {
+	entry := Delivery{
+		ID:         del.id,
+		URL:        del.hook.URL,
+		Event:      del.event,
+		Attempt:    del.attempt,
+		Status:     status,
+		StatusCode: code,
+		Time:       time.Now(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.log = append(d.log, entry)
+	if len(d.log) > d.logSize {
+		d.log = append(d.log[:0:0], d.log[len(d.log)-d.logSize:]...)
+	}
+}
+
+// newDeliveryID returns a random ID identifying a delivery across attempts.
+func newDeliveryID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
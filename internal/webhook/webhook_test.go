@@ -0,0 +1,192 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flohansen/documenter/internal/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatcher(t *testing.T) {
+	event := webhook.Event{
+		Type:      webhook.EventDocumentationChanged,
+		Namespace: "default",
+		Name:      "service",
+		Hash:      "abc",
+		Time:      time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	statuses := func(deliveries []webhook.Delivery) []webhook.DeliveryStatus {
+		var got []webhook.DeliveryStatus
+		for _, d := range deliveries {
+			got = append(got, d.Status)
+		}
+		return got
+	}
+
+	t.Run("should deliver signed event to receiver", func(t *testing.T) {
+		// assign
+		type request struct {
+			header http.Header
+			body   []byte
+		}
+		requests := make(chan request, 1)
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			requests <- request{header: r.Header, body: body}
+		}))
+		defer receiver.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		dispatcher := webhook.NewDispatcher()
+		go dispatcher.Run(ctx)
+
+		// act
+		dispatcher.Send(webhook.Hook{URL: receiver.URL, Secret: "secret"}, event)
+
+		// assert
+		select {
+		case req := <-requests:
+			var got webhook.Event
+			assert.NoError(t, json.Unmarshal(req.body, &got))
+			assert.Equal(t, event, got)
+			assert.Equal(t, webhook.EventDocumentationChanged, req.header.Get(webhook.EventHeader))
+			assert.Equal(t, webhook.Sign("secret", req.body), req.header.Get(webhook.SignatureHeader))
+			assert.NotEmpty(t, req.header.Get(webhook.DeliveryHeader))
+		case <-time.After(5 * time.Second):
+			t.Fatal("receiver was not called")
+		}
+		assert.Eventually(t, func() bool {
+			return len(dispatcher.Deliveries()) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, webhook.DeliveryStatusDelivered, dispatcher.Deliveries()[0].Status)
+		assert.Equal(t, http.StatusOK, dispatcher.Deliveries()[0].StatusCode)
+	})
+
+	t.Run("should not sign event without secret", func(t *testing.T) {
+		// assign
+		signatures := make(chan string, 1)
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			signatures <- r.Header.Get(webhook.SignatureHeader)
+		}))
+		defer receiver.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		dispatcher := webhook.NewDispatcher()
+		go dispatcher.Run(ctx)
+
+		// act
+		dispatcher.Send(webhook.Hook{URL: receiver.URL}, event)
+
+		// assert
+		select {
+		case signature := <-signatures:
+			assert.Empty(t, signature)
+		case <-time.After(5 * time.Second):
+			t.Fatal("receiver was not called")
+		}
+	})
+
+	t.Run("should retry failed deliveries", func(t *testing.T) {
+		// assign
+		var calls atomic.Int32
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer receiver.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		dispatcher := webhook.NewDispatcher(webhook.WithBackoff(time.Millisecond))
+		go dispatcher.Run(ctx)
+
+		// act
+		dispatcher.Send(webhook.Hook{URL: receiver.URL}, event)
+
+		// assert
+		assert.Eventually(t, func() bool {
+			return len(dispatcher.Deliveries()) == 3
+		}, 5*time.Second, 10*time.Millisecond)
+		deliveries := dispatcher.Deliveries()
+		assert.Equal(t, []webhook.DeliveryStatus{
+			webhook.DeliveryStatusRetrying,
+			webhook.DeliveryStatusRetrying,
+			webhook.DeliveryStatusDelivered,
+		}, statuses(deliveries))
+		assert.Equal(t, http.StatusServiceUnavailable, deliveries[0].StatusCode)
+		assert.Equal(t, "unexpected status code 503", deliveries[0].Error)
+		assert.Equal(t, 3, deliveries[2].Attempt)
+		assert.Equal(t, deliveries[0].ID, deliveries[2].ID)
+	})
+
+	t.Run("should give up after max attempts", func(t *testing.T) {
+		// assign
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer receiver.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		dispatcher := webhook.NewDispatcher(webhook.WithBackoff(time.Millisecond), webhook.WithMaxAttempts(2))
+		go dispatcher.Run(ctx)
+
+		// act
+		dispatcher.Send(webhook.Hook{URL: receiver.URL}, event)
+
+		// assert
+		assert.Eventually(t, func() bool {
+			deliveries := dispatcher.Deliveries()
+			return len(deliveries) == 2 && deliveries[1].Status == webhook.DeliveryStatusFailed
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, []webhook.DeliveryStatus{
+			webhook.DeliveryStatusRetrying,
+			webhook.DeliveryStatusFailed,
+		}, statuses(dispatcher.Deliveries()))
+	})
+
+	t.Run("should drop deliveries if queue is full", func(t *testing.T) {
+		// assign
+		dispatcher := webhook.NewDispatcher(webhook.WithQueueSize(1))
+
+		// act
+		dispatcher.Send(webhook.Hook{URL: "http://localhost"}, event)
+		dispatcher.Send(webhook.Hook{URL: "http://localhost"}, event)
+
+		// assert
+		deliveries := dispatcher.Deliveries()
+		if assert.Len(t, deliveries, 1) {
+			assert.Equal(t, webhook.DeliveryStatusDropped, deliveries[0].Status)
+			assert.Equal(t, "delivery queue is full", deliveries[0].Error)
+		}
+	})
+
+	t.Run("should keep latest deliveries in log", func(t *testing.T) {
+		// assign
+		dispatcher := webhook.NewDispatcher(webhook.WithQueueSize(1), webhook.WithLogSize(2))
+		dispatcher.Send(webhook.Hook{URL: "http://localhost"}, event)
+
+		// act
+		for _, name := range []string{"a", "b", "c"} {
+			dispatcher.Send(webhook.Hook{URL: "http://localhost/" + name}, event)
+		}
+
+		// assert
+		deliveries := dispatcher.Deliveries()
+		if assert.Len(t, deliveries, 2) {
+			assert.Equal(t, "http://localhost/b", deliveries[0].URL)
+			assert.Equal(t, "http://localhost/c", deliveries[1].URL)
+		}
+	})
+}
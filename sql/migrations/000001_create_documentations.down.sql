@@ -1 +1,9 @@
-DROP TABLE IF EXISTS documentations;
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM documentations) THEN
+        RAISE EXCEPTION 'refusing to drop table documentations, it still contains documentations';
+    END IF;
+END
+$$;
+
+DROP TABLE documentations;
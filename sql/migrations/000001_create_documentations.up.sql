@@ -1,4 +1,4 @@
-CREATE TABLE IF NOT EXISTS documentations (
+CREATE TABLE documentations (
     id serial PRIMARY KEY,
     name varchar(255) UNIQUE NOT NULL,
     content bytea NOT NULL
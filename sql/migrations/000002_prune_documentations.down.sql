@@ -0,0 +1,11 @@
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM documentations_archive) THEN
+        RAISE EXCEPTION 'refusing to drop table documentations_archive, it still contains archived documentations';
+    END IF;
+END
+$$;
+
+DROP TABLE documentations_archive;
+
+ALTER TABLE documentations DROP COLUMN stale;
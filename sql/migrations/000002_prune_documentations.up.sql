@@ -0,0 +1,8 @@
+ALTER TABLE documentations ADD COLUMN stale boolean NOT NULL DEFAULT false;
+
+CREATE TABLE documentations_archive (
+    id serial PRIMARY KEY,
+    name varchar(255) NOT NULL,
+    content bytea NOT NULL,
+    archived_at timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,17 @@
+DROP INDEX documentations_category_idx;
+DROP INDEX documentations_tags_idx;
+DROP INDEX documentations_owner_idx;
+
+ALTER TABLE documentations_archive
+    DROP COLUMN category,
+    DROP COLUMN tags,
+    DROP COLUMN owner,
+    DROP COLUMN description,
+    DROP COLUMN title;
+
+ALTER TABLE documentations
+    DROP COLUMN category,
+    DROP COLUMN tags,
+    DROP COLUMN owner,
+    DROP COLUMN description,
+    DROP COLUMN title;
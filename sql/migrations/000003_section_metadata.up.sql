@@ -0,0 +1,17 @@
+ALTER TABLE documentations
+    ADD COLUMN title text NOT NULL DEFAULT '',
+    ADD COLUMN description text NOT NULL DEFAULT '',
+    ADD COLUMN owner text NOT NULL DEFAULT '',
+    ADD COLUMN tags text[] NOT NULL DEFAULT '{}',
+    ADD COLUMN category text NOT NULL DEFAULT '';
+
+ALTER TABLE documentations_archive
+    ADD COLUMN title text NOT NULL DEFAULT '',
+    ADD COLUMN description text NOT NULL DEFAULT '',
+    ADD COLUMN owner text NOT NULL DEFAULT '',
+    ADD COLUMN tags text[] NOT NULL DEFAULT '{}',
+    ADD COLUMN category text NOT NULL DEFAULT '';
+
+CREATE INDEX documentations_owner_idx ON documentations (owner);
+CREATE INDEX documentations_tags_idx ON documentations USING gin (tags);
+CREATE INDEX documentations_category_idx ON documentations (category text_pattern_ops);
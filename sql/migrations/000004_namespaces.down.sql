@@ -0,0 +1,14 @@
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM documentations WHERE namespace <> 'default')
+        OR EXISTS (SELECT 1 FROM documentations_archive WHERE namespace <> 'default') THEN
+        RAISE EXCEPTION 'refusing to drop column namespace, documentations outside of the default namespace exist';
+    END IF;
+END
+$$;
+
+ALTER TABLE documentations_archive DROP COLUMN namespace;
+
+ALTER TABLE documentations DROP CONSTRAINT documentations_namespace_name_key;
+ALTER TABLE documentations ADD CONSTRAINT documentations_name_key UNIQUE (name);
+ALTER TABLE documentations DROP COLUMN namespace;
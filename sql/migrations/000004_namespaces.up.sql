@@ -0,0 +1,5 @@
+ALTER TABLE documentations ADD COLUMN namespace text NOT NULL DEFAULT 'default';
+ALTER TABLE documentations DROP CONSTRAINT documentations_name_key;
+ALTER TABLE documentations ADD CONSTRAINT documentations_namespace_name_key UNIQUE (namespace, name);
+
+ALTER TABLE documentations_archive ADD COLUMN namespace text NOT NULL DEFAULT 'default';
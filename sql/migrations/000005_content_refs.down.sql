@@ -0,0 +1,11 @@
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM documentations WHERE content_ref <> '')
+        OR EXISTS (SELECT 1 FROM documentations_archive WHERE content_ref <> '') THEN
+        RAISE EXCEPTION 'refusing to drop column content_ref, documentations with contents in object storage exist';
+    END IF;
+END
+$$;
+
+ALTER TABLE documentations_archive DROP COLUMN content_ref;
+ALTER TABLE documentations DROP COLUMN content_ref;
@@ -0,0 +1,2 @@
+ALTER TABLE documentations ADD COLUMN content_ref text NOT NULL DEFAULT '';
+ALTER TABLE documentations_archive ADD COLUMN content_ref text NOT NULL DEFAULT '';
@@ -0,0 +1,11 @@
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM documentations WHERE content_codec <> '')
+        OR EXISTS (SELECT 1 FROM documentations_archive WHERE content_codec <> '') THEN
+        RAISE EXCEPTION 'refusing to drop column content_codec, compressed documentations exist';
+    END IF;
+END
+$$;
+
+ALTER TABLE documentations_archive DROP COLUMN content_codec;
+ALTER TABLE documentations DROP COLUMN content_codec;
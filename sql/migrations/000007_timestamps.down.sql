@@ -0,0 +1,13 @@
+DROP INDEX documentations_updated_at_idx;
+
+ALTER TABLE documentations_archive
+    DROP COLUMN last_scraped_at,
+    DROP COLUMN updated_at,
+    DROP COLUMN created_at,
+    DROP COLUMN content_hash;
+
+ALTER TABLE documentations
+    DROP COLUMN last_scraped_at,
+    DROP COLUMN updated_at,
+    DROP COLUMN created_at,
+    DROP COLUMN content_hash;
@@ -0,0 +1,19 @@
+ALTER TABLE documentations
+    ADD COLUMN content_hash text NOT NULL DEFAULT '',
+    ADD COLUMN created_at timestamptz NOT NULL DEFAULT now(),
+    ADD COLUMN updated_at timestamptz NOT NULL DEFAULT now(),
+    ADD COLUMN last_scraped_at timestamptz NOT NULL DEFAULT now();
+
+ALTER TABLE documentations_archive
+    ADD COLUMN content_hash text NOT NULL DEFAULT '',
+    ADD COLUMN created_at timestamptz NOT NULL DEFAULT now(),
+    ADD COLUMN updated_at timestamptz NOT NULL DEFAULT now(),
+    ADD COLUMN last_scraped_at timestamptz NOT NULL DEFAULT now();
+
+-- Uncompressed contents in the database are hashed right away, so unchanged
+-- documentations keep their updated_at on the next scrape. All other contents
+-- are hashed on their next scrape.
+UPDATE documentations SET content_hash = encode(sha256(content), 'hex')
+WHERE content_ref = '' AND content_codec = '';
+
+CREATE INDEX documentations_updated_at_idx ON documentations (namespace, updated_at DESC, name DESC);
@@ -0,0 +1,2 @@
+DROP TRIGGER documentations_notify_change ON documentations;
+DROP FUNCTION notify_documentation_change();
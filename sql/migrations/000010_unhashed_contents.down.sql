@@ -0,0 +1,13 @@
+CREATE OR REPLACE FUNCTION notify_documentation_change() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR NEW.content_hash IS DISTINCT FROM OLD.content_hash THEN
+        PERFORM pg_notify('documentation_changes', json_build_object(
+            'namespace', NEW.namespace,
+            'name', NEW.name,
+            'hash', NEW.content_hash
+        )::text);
+    END IF;
+
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,20 @@
+-- Contents which were compressed or stored in a blob store before content
+-- hashes were introduced have an empty hash until their next scrape. Setting
+-- their hash without changing the content does not advance updated_at, so it
+-- is not published as change.
+CREATE OR REPLACE FUNCTION notify_documentation_change() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR (
+        NEW.content_hash IS DISTINCT FROM OLD.content_hash
+        AND (OLD.content_hash <> '' OR NEW.updated_at IS DISTINCT FROM OLD.updated_at)
+    ) THEN
+        PERFORM pg_notify('documentation_changes', json_build_object(
+            'namespace', NEW.namespace,
+            'name', NEW.name,
+            'hash', NEW.content_hash
+        )::text);
+    END IF;
+
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
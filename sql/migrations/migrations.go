@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migrations of the database schema, so
+// they can be applied by the importer binary itself.
+package migrations
+
+import "embed"
+
+// FS contains the up and down migrations in the format of golang-migrate.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -1,5 +1,111 @@
--- name: UpsertDocumentation :exec
-INSERT INTO documentations (name, content)
-VALUES ($1, $2)
-ON CONFLICT (name)
-    DO UPDATE SET content = excluded.content;
+-- name: UpsertDocumentations :batchone
+INSERT INTO documentations (namespace, name, section, content, content_ref, content_codec, content_hash, title, description, owner, tags, category)
+VALUES (@namespace, @name, @section, @content, @content_ref, @content_codec, @content_hash, @title, @description, @owner, COALESCE(@tags::text[], '{}'), @category)
+ON CONFLICT (namespace, name)
+    DO UPDATE SET
+        section = excluded.section,
+        content = excluded.content,
+        content_ref = excluded.content_ref,
+        content_codec = excluded.content_codec,
+        content_hash = excluded.content_hash,
+        title = excluded.title,
+        description = excluded.description,
+        owner = excluded.owner,
+        tags = excluded.tags,
+        category = excluded.category,
+        stale = false,
+        updated_at = CASE
+            WHEN documentations.content_hash = excluded.content_hash THEN documentations.updated_at
+            -- Contents stored before hashes were introduced may lack a hash,
+            -- but are unchanged if they were stored the same way.
+            WHEN documentations.content_hash = ''
+                AND documentations.content = excluded.content
+                AND documentations.content_ref = excluded.content_ref
+                AND documentations.content_codec = excluded.content_codec THEN documentations.updated_at
+            ELSE now()
+        END,
+        last_scraped_at = now()
+RETURNING updated_at = last_scraped_at AS changed;
+
+-- name: ListSectionContentRefs :many
+SELECT content_ref FROM documentations
+WHERE namespace = @namespace
+  AND section = @section
+  AND content_ref <> '';
+
+-- name: DeleteSectionDocumentationsExcept :execrows
+DELETE FROM documentations
+WHERE namespace = @namespace
+  AND section = @section
+  AND NOT (name = ANY(@names::text[]));
+
+-- name: GetContentRef :one
+SELECT content_ref FROM documentations
+WHERE namespace = @namespace AND name = @name;
+
+-- name: IsContentReferenced :one
+SELECT EXISTS (SELECT 1 FROM documentations WHERE content_ref = @content_ref)
+    OR EXISTS (SELECT 1 FROM documentations_archive WHERE content_ref = @content_ref);
+
+-- name: ListDocumentations :many
+SELECT * FROM documentations
+WHERE namespace = @namespace
+  AND (@owner::text = '' OR owner = @owner)
+  AND tags @> COALESCE(@tags::text[], '{}')
+  AND (@category::text = '' OR category = @category OR starts_with(category, @category || '/'))
+ORDER BY category, name;
+
+-- name: GetDocumentation :one
+SELECT * FROM documentations
+WHERE namespace = @namespace AND name = @name;
+
+-- name: ListDocumentationsByName :many
+SELECT * FROM documentations
+WHERE namespace = @namespace
+  AND (@owner::text = '' OR owner = @owner)
+  AND tags @> COALESCE(@tags::text[], '{}')
+  AND (@category::text = '' OR category = @category OR starts_with(category, @category || '/'))
+  AND name > @after_name::text
+ORDER BY name
+LIMIT @page_size::int;
+
+-- name: ListDocumentationsByUpdated :many
+SELECT * FROM documentations
+WHERE namespace = @namespace
+  AND (@owner::text = '' OR owner = @owner)
+  AND tags @> COALESCE(@tags::text[], '{}')
+  AND (@category::text = '' OR category = @category OR starts_with(category, @category || '/'))
+  AND (sqlc.narg(after_updated_at)::timestamptz IS NULL OR (updated_at, name) < (sqlc.narg(after_updated_at)::timestamptz, @after_name::text))
+ORDER BY updated_at DESC, name DESC
+LIMIT @page_size::int;
+
+-- name: CountDocumentations :one
+SELECT count(*) FROM documentations
+WHERE namespace = @namespace
+  AND (@owner::text = '' OR owner = @owner)
+  AND tags @> COALESCE(@tags::text[], '{}')
+  AND (@category::text = '' OR category = @category OR starts_with(category, @category || '/'));
+
+-- name: DeleteDocumentationsExcept :many
+DELETE FROM documentations
+WHERE (namespace, section) NOT IN (
+    SELECT k.namespace, k.section FROM unnest(@namespaces::text[], @sections::text[]) AS k (namespace, section)
+)
+RETURNING content_ref;
+
+-- name: ArchiveDocumentationsExcept :execrows
+WITH archived AS (
+    DELETE FROM documentations
+    WHERE (namespace, section) NOT IN (
+        SELECT k.namespace, k.section FROM unnest(@namespaces::text[], @sections::text[]) AS k (namespace, section)
+    )
+    RETURNING namespace, name, content, content_ref, content_codec, content_hash, title, description, owner, tags, category, created_at, updated_at, last_scraped_at
+)
+INSERT INTO documentations_archive (namespace, name, content, content_ref, content_codec, content_hash, title, description, owner, tags, category, created_at, updated_at, last_scraped_at)
+SELECT namespace, name, content, content_ref, content_codec, content_hash, title, description, owner, tags, category, created_at, updated_at, last_scraped_at FROM archived;
+
+-- name: MarkDocumentationsStaleExcept :execrows
+UPDATE documentations SET stale = true
+WHERE (namespace, section) NOT IN (
+    SELECT k.namespace, k.section FROM unnest(@namespaces::text[], @sections::text[]) AS k (namespace, section)
+) AND NOT stale;
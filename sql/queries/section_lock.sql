@@ -0,0 +1,5 @@
+-- name: TryLockSection :one
+SELECT pg_try_advisory_lock(hashtext('documenter.section'), hashtext(@name::text));
+
+-- name: UnlockSection :one
+SELECT pg_advisory_unlock(hashtext('documenter.section'), hashtext(@name::text));
@@ -0,0 +1,16 @@
+-- SQLite only raises errors within triggers, so a temporary trigger aborts
+-- the migration if documentations would be dropped.
+CREATE TEMP TABLE drop_guard (populated boolean);
+CREATE TEMP TRIGGER drop_guard_abort BEFORE INSERT ON drop_guard
+BEGIN
+    SELECT RAISE(ABORT, 'refusing to drop tables documentations and documentations_archive, they still contain documentations');
+END;
+
+INSERT INTO drop_guard
+SELECT true WHERE EXISTS (SELECT 1 FROM documentations)
+    OR EXISTS (SELECT 1 FROM documentations_archive);
+
+DROP TABLE drop_guard;
+
+DROP TABLE documentations_archive;
+DROP TABLE documentations;
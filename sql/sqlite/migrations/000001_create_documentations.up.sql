@@ -0,0 +1,29 @@
+CREATE TABLE documentations (
+    id integer PRIMARY KEY,
+    namespace text NOT NULL DEFAULT 'default',
+    name text NOT NULL,
+    content blob NOT NULL,
+    stale boolean NOT NULL DEFAULT false,
+    title text NOT NULL DEFAULT '',
+    description text NOT NULL DEFAULT '',
+    owner text NOT NULL DEFAULT '',
+    tags text NOT NULL DEFAULT '[]',
+    category text NOT NULL DEFAULT '',
+    UNIQUE (namespace, name)
+);
+
+CREATE TABLE documentations_archive (
+    id integer PRIMARY KEY,
+    namespace text NOT NULL DEFAULT 'default',
+    name text NOT NULL,
+    content blob NOT NULL,
+    archived_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    title text NOT NULL DEFAULT '',
+    description text NOT NULL DEFAULT '',
+    owner text NOT NULL DEFAULT '',
+    tags text NOT NULL DEFAULT '[]',
+    category text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX documentations_owner_idx ON documentations (owner);
+CREATE INDEX documentations_category_idx ON documentations (category);
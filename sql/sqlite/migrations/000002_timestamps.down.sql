@@ -0,0 +1,7 @@
+ALTER TABLE documentations_archive DROP COLUMN last_scraped_at;
+ALTER TABLE documentations_archive DROP COLUMN updated_at;
+ALTER TABLE documentations_archive DROP COLUMN created_at;
+
+ALTER TABLE documentations DROP COLUMN last_scraped_at;
+ALTER TABLE documentations DROP COLUMN updated_at;
+ALTER TABLE documentations DROP COLUMN created_at;
@@ -0,0 +1,19 @@
+-- SQLite cannot add columns with non-constant defaults, so existing rows are
+-- updated afterwards. New rows always get their timestamps from the importer.
+ALTER TABLE documentations ADD COLUMN created_at datetime NOT NULL DEFAULT '1970-01-01 00:00:00';
+ALTER TABLE documentations ADD COLUMN updated_at datetime NOT NULL DEFAULT '1970-01-01 00:00:00';
+ALTER TABLE documentations ADD COLUMN last_scraped_at datetime NOT NULL DEFAULT '1970-01-01 00:00:00';
+
+ALTER TABLE documentations_archive ADD COLUMN created_at datetime NOT NULL DEFAULT '1970-01-01 00:00:00';
+ALTER TABLE documentations_archive ADD COLUMN updated_at datetime NOT NULL DEFAULT '1970-01-01 00:00:00';
+ALTER TABLE documentations_archive ADD COLUMN last_scraped_at datetime NOT NULL DEFAULT '1970-01-01 00:00:00';
+
+UPDATE documentations SET
+    created_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP,
+    last_scraped_at = CURRENT_TIMESTAMP;
+
+UPDATE documentations_archive SET
+    created_at = archived_at,
+    updated_at = archived_at,
+    last_scraped_at = archived_at;
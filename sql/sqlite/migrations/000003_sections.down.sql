@@ -0,0 +1,2 @@
+DROP INDEX documentations_section_idx;
+ALTER TABLE documentations DROP COLUMN section;
@@ -0,0 +1,7 @@
+ALTER TABLE documentations ADD COLUMN section text NOT NULL DEFAULT '';
+
+-- Every documentation written so far was the single documentation of the
+-- section with the same name.
+UPDATE documentations SET section = name;
+
+CREATE INDEX documentations_section_idx ON documentations (namespace, section);
@@ -0,0 +1,95 @@
+-- name: UpsertDocumentation :one
+INSERT INTO documentations (namespace, name, section, content, title, description, owner, tags, category, created_at, updated_at, last_scraped_at)
+VALUES (@namespace, @name, @section, @content, @title, @description, @owner, @tags, @category, @now, @now, @now)
+ON CONFLICT (namespace, name)
+    DO UPDATE SET
+        section = excluded.section,
+        content = excluded.content,
+        title = excluded.title,
+        description = excluded.description,
+        owner = excluded.owner,
+        tags = excluded.tags,
+        category = excluded.category,
+        stale = false,
+        updated_at = CASE
+            WHEN documentations.content = excluded.content THEN documentations.updated_at
+            ELSE excluded.updated_at
+        END,
+        last_scraped_at = excluded.last_scraped_at
+RETURNING CAST(updated_at = last_scraped_at AS BOOLEAN) AS changed;
+
+-- name: DeleteSectionDocumentationsExcept :execrows
+DELETE FROM documentations
+WHERE namespace = @namespace
+  AND section = @section
+  AND name NOT IN (SELECT value FROM json_each(@names));
+
+-- name: ListDocumentations :many
+SELECT * FROM documentations
+WHERE namespace = @namespace
+  AND (@owner = '' OR owner = @owner)
+  AND NOT EXISTS (
+      SELECT 1 FROM json_each(@tags) AS t
+      WHERE t.value NOT IN (SELECT value FROM json_each(documentations.tags))
+  )
+  AND (@category = '' OR category = @category OR substr(category, 1, length(@category) + 1) = @category || '/')
+ORDER BY category, name;
+
+-- name: DeleteDocumentationsExcept :execrows
+DELETE FROM documentations
+WHERE (namespace, section) NOT IN (
+    SELECT ns.value, s.value FROM json_each(@namespaces) AS ns JOIN json_each(@sections) AS s ON s.key = ns.key
+);
+
+-- name: CopyDocumentationsToArchiveExcept :execrows
+INSERT INTO documentations_archive (namespace, name, content, title, description, owner, tags, category, created_at, updated_at, last_scraped_at)
+SELECT namespace, name, content, title, description, owner, tags, category, created_at, updated_at, last_scraped_at FROM documentations
+WHERE (namespace, section) NOT IN (
+    SELECT ns.value, s.value FROM json_each(@namespaces) AS ns JOIN json_each(@sections) AS s ON s.key = ns.key
+);
+
+-- name: MarkDocumentationsStaleExcept :execrows
+UPDATE documentations SET stale = true
+WHERE (namespace, section) NOT IN (
+    SELECT ns.value, s.value FROM json_each(@namespaces) AS ns JOIN json_each(@sections) AS s ON s.key = ns.key
+) AND NOT stale;
+
+-- name: GetDocumentation :one
+SELECT * FROM documentations
+WHERE namespace = @namespace AND name = @name;
+
+-- name: ListDocumentationsByName :many
+SELECT * FROM documentations
+WHERE namespace = @namespace
+  AND (@owner = '' OR owner = @owner)
+  AND NOT EXISTS (
+      SELECT 1 FROM json_each(@tags) AS t
+      WHERE t.value NOT IN (SELECT value FROM json_each(documentations.tags))
+  )
+  AND (@category = '' OR category = @category OR substr(category, 1, length(@category) + 1) = @category || '/')
+  AND name > @after_name
+ORDER BY name
+LIMIT @page_size;
+
+-- name: ListDocumentationsByUpdated :many
+SELECT * FROM documentations
+WHERE namespace = @namespace
+  AND (@owner = '' OR owner = @owner)
+  AND NOT EXISTS (
+      SELECT 1 FROM json_each(@tags) AS t
+      WHERE t.value NOT IN (SELECT value FROM json_each(documentations.tags))
+  )
+  AND (@category = '' OR category = @category OR substr(category, 1, length(@category) + 1) = @category || '/')
+  AND (sqlc.narg(after_updated_at) IS NULL OR (updated_at, name) < (sqlc.narg(after_updated_at), @after_name))
+ORDER BY updated_at DESC, name DESC
+LIMIT @page_size;
+
+-- name: CountDocumentations :one
+SELECT count(*) FROM documentations
+WHERE namespace = @namespace
+  AND (@owner = '' OR owner = @owner)
+  AND NOT EXISTS (
+      SELECT 1 FROM json_each(@tags) AS t
+      WHERE t.value NOT IN (SELECT value FROM json_each(documentations.tags))
+  )
+  AND (@category = '' OR category = @category OR substr(category, 1, length(@category) + 1) = @category || '/');
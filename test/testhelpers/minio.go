@@ -0,0 +1,95 @@
+package testhelpers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	MinioAccessKey = "minioadmin"
+	MinioSecretKey = "minioadmin"
+)
+
+type MinioContainer struct {
+	t         *testing.T
+	container testcontainers.Container
+}
+
+// Endpoint returns the S3 API endpoint in the form host:port.
+func (c *MinioContainer) Endpoint() string {
+	host, err := c.container.Host(context.Background())
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	port, err := c.container.MappedPort(context.Background(), nat.Port("9000/tcp"))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	return fmt.Sprintf("%s:%d", host, port.Int())
+}
+
+func StartMinioContainer(t *testing.T, opts ...MinioContainerOption) *MinioContainer {
+	container, err := testcontainers.GenericContainer(context.Background(), testcontainers.GenericContainerRequest{
+		Started: true,
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "minio/minio:RELEASE.2025-04-22T22-12-26Z",
+			ExposedPorts: []string{"9000/tcp"},
+			Cmd:          []string{"server", "/data"},
+			Env: map[string]string{
+				"MINIO_ROOT_USER":     MinioAccessKey,
+				"MINIO_ROOT_PASSWORD": MinioSecretKey,
+			},
+			HostConfigModifier: func(hc *container.HostConfig) {
+				hc.AutoRemove = true
+			},
+			WaitingFor: wait.ForAll(
+				wait.ForListeningPort(nat.Port("9000/tcp")),
+				wait.ForHTTP("/minio/health/live").WithPort(nat.Port("9000/tcp")),
+			),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		container.Terminate(context.Background())
+	})
+
+	c := &MinioContainer{
+		t:         t,
+		container: container,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+type MinioContainerOption func(*MinioContainer)
+
+func WithBucket(bucket string) MinioContainerOption {
+	return func(c *MinioContainer) {
+		client, err := minio.New(c.Endpoint(), &minio.Options{
+			Creds: credentials.NewStaticV4(MinioAccessKey, MinioSecretKey, ""),
+		})
+		if err != nil {
+			c.t.Fatal(err)
+		}
+
+		if err := client.MakeBucket(context.Background(), bucket, minio.MakeBucketOptions{}); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+}